@@ -0,0 +1,97 @@
+package app
+
+import (
+	"errors"
+	"fman/element"
+	"fman/fileutil"
+	"fman/sys"
+	"fmt"
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/widget"
+	"path/filepath"
+	"strconv"
+)
+
+/*
+
+  File:    archiveViewer.go
+  Author:  Bob Shofner
+
+  MIT License - https://opensource.org/license/mit/
+
+  This permission notice shall be included in all copies
+    or substantial portions of the Software.
+
+*/
+/*
+  Description: a window with the table of contents of a zip, tar or gzip,
+  without extracting it. Files that aren't archives are shown as text.
+*/
+
+// at most this many entries are listed
+const maxArchiveEntries = 100000
+
+// NewArchiveViewer lists the entries of an archive
+func NewArchiveViewer(system *sys.System, path string) {
+	name := filepath.Base(path)
+	system.BusyIndicator.Start()
+	entries, err := fileutil.ListArchive(path, maxArchiveEntries, func() bool {
+		system.BusyIndicator.Refresh()
+		return false
+	})
+	system.BusyIndicator.Stop()
+	if errors.Is(err, fileutil.ErrNotArchive) {
+		NewViewer(system, path)
+		return
+	}
+	if err != nil {
+		sys.Toast(fmt.Sprintf("%s: %s", name, err), sys.ErrorToast)
+		if len(entries) == 0 {
+			return
+		}
+	}
+	if len(entries) == maxArchiveEntries {
+		sys.Toast(fmt.Sprintf("%s: the first %d entries", name, maxArchiveEntries), sys.WarnToast)
+	}
+
+	links := false
+	for _, entry := range entries {
+		links = links || entry.Link != ""
+	}
+	heading := []string{"Name", "Size", "Compressed", "Modified", "Mode"}
+	if links {
+		heading = append(heading, "Link")
+	}
+	records := make([][]string, 0, len(entries)+1)
+	records = append(records, heading)
+	for _, entry := range entries {
+		record := []string{entry.Name, "", "", entry.Modified.Format("2006-01-02 15:04:05"), entry.Mode.String()}
+		if entry.Size >= 0 {
+			record[1] = strconv.FormatInt(entry.Size, 10)
+		}
+		if entry.Compressed >= 0 {
+			record[2] = strconv.FormatInt(entry.Compressed, 10)
+		}
+		if links {
+			record = append(record, entry.Link)
+		}
+		records = append(records, record)
+	}
+
+	hexView := widget.NewButton("HEX VIEW", func() {
+		NewHexViewer(system, path)
+	})
+	content := element.NewTableViewer(name, records, []*widget.Button{hexView}).Content
+
+	id := fmt.Sprintf("Viewer(%d)", viewerCount)
+	viewerCount++
+	w := fyne.CurrentApp().NewWindow(id)
+	w.SetContent(content)
+	w.Resize(fyne.NewSize(800, 500))
+	openWindows[id] = w
+	w.SetOnClosed(func() {
+		delete(openWindows, id)
+	})
+	w.SetFixedSize(false)
+	w.Show()
+}
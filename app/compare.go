@@ -0,0 +1,180 @@
+package app
+
+import (
+	"fman/element"
+	"fman/fileutil"
+	"fman/sys"
+	"fmt"
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"io"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+)
+
+/*
+
+  File:    compare.go
+  Author:  Bob Shofner
+
+  MIT License - https://opensource.org/license/mit/
+
+  This permission notice shall be included in all copies
+    or substantial portions of the Software.
+
+*/
+/*
+  Description: a window comparing two files side by side,
+  as text, or byte by byte if either is not text.
+*/
+
+var compareCount = 1
+
+func NewCompare(system *sys.System, left, right string) {
+	paths := [2]string{left, right}
+	var texts [2]string
+	var formats [2]fileutil.TextFormat
+	binary := false
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			sys.Toast(err.Error(), sys.ErrorToast)
+			return
+		}
+		if info.IsDir() {
+			sys.Toast(fmt.Sprintf("%s is a Directory", filepath.Base(path)), sys.WarnToast)
+			return
+		}
+		binary = binary || looksBinary(path)
+	}
+	if binary {
+		newBinaryCompare(left, right)
+		return
+	}
+	for side, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			sys.Toast(err.Error(), sys.ErrorToast)
+			return
+		}
+		if info.Size() > maxEditSize {
+			sys.Toast(fmt.Sprintf("%s is too large to compare", filepath.Base(path)), sys.WarnToast)
+			return
+		}
+		texts[side], formats[side], err = fileutil.ReadText(path)
+		if err != nil {
+			sys.Toast(err.Error(), sys.ErrorToast)
+			return
+		}
+	}
+	id := fmt.Sprintf("Compare(%d)", compareCount)
+	compareCount++
+	w := fyne.CurrentApp().NewWindow(id)
+	names := [2]string{left, right}
+	if filepath.Dir(left) == filepath.Dir(right) {
+		names = [2]string{filepath.Base(left), filepath.Base(right)}
+	}
+	diff := element.NewTextDiff(w, names[0], texts[0], names[1], texts[1], nil, 4)
+	diff.OnSave = func(right bool, text string) error {
+		side := 0
+		if right {
+			side = 1
+		}
+		return fileutil.WriteText(paths[side], text, formats[side])
+	}
+	w.SetContent(diff.Content)
+	w.Resize(fyne.NewSize(1000, 600))
+	openWindows[id] = w
+	unsavedWindows[id] = diff.Edited
+	w.SetCloseIntercept(func() {
+		if !diff.Edited() {
+			w.Close()
+			return
+		}
+		dialog.ShowConfirm("Unsaved Changes", "Discard the copied changes?", func(yes bool) {
+			if yes {
+				w.Close()
+			}
+		}, w)
+	})
+	w.SetOnClosed(func() {
+		delete(openWindows, id)
+		delete(unsavedWindows, id)
+	})
+	w.SetFixedSize(false)
+	w.Show()
+}
+
+// looksBinary checks the start of a file
+func looksBinary(path string) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	sample := make([]byte, 8*1024)
+	n, _ := io.ReadFull(f, sample)
+	return fileutil.LooksBinary(sample[:n])
+}
+
+// newBinaryCompare compares in the background, showing both files in hex
+func newBinaryCompare(left, right string) {
+	var files [2]*os.File
+	var readers [2]io.ReaderAt
+	var sizes [2]int64
+	closeFiles := func() {
+		for _, f := range files {
+			if f != nil {
+				_ = f.Close()
+			}
+		}
+	}
+	for side, path := range []string{left, right} {
+		f, err := os.Open(path)
+		if err != nil {
+			closeFiles()
+			sys.Toast(err.Error(), sys.ErrorToast)
+			return
+		}
+		files[side] = f
+		readers[side] = f
+		info, err := f.Stat()
+		if err != nil {
+			closeFiles()
+			sys.Toast(err.Error(), sys.ErrorToast)
+			return
+		}
+		sizes[side] = info.Size()
+	}
+	id := fmt.Sprintf("Compare(%d)", compareCount)
+	compareCount++
+	w := fyne.CurrentApp().NewWindow(id)
+	names := [2]string{left, right}
+	if filepath.Dir(left) == filepath.Dir(right) {
+		names = [2]string{filepath.Base(left), filepath.Base(right)}
+	}
+	hc := element.NewHexCompare(names, readers, sizes, nil)
+	var closed atomic.Bool
+	w.SetContent(hc.Content)
+	w.Resize(fyne.NewSize(1200, 500))
+	openWindows[id] = w
+	w.SetOnClosed(func() {
+		closed.Store(true)
+		closeFiles()
+		delete(openWindows, id)
+	})
+	w.SetFixedSize(false)
+	w.Show()
+	go func() {
+		result, err := fileutil.CompareBytes(left, right, closed.Load)
+		if closed.Load() {
+			return
+		}
+		fyne.Do(func() {
+			hc.SetResult(result, err)
+		})
+	}()
+}
@@ -0,0 +1,77 @@
+package app
+
+/*
+
+  File:    convert.go
+  Author:  Bob Shofner
+
+  MIT License - https://opensource.org/license/mit/
+
+  This permission notice shall be included in all copies
+    or substantial portions of the Software.
+
+  Convert the character encoding and / or line endings of text files.
+
+*/
+
+import (
+	"fman/fileutil"
+	"fman/sys"
+	"fmt"
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"path/filepath"
+)
+
+var convertDetect = "Detect"
+var convertUnchanged = "Unchanged"
+
+// ConvertText - a form dialog to convert files, done is called after
+func ConvertText(window fyne.Window, paths []string, done func()) {
+	from := widget.NewSelect(append([]string{convertDetect}, fileutil.Encodings...), nil)
+	from.SetSelected(convertDetect)
+	to := widget.NewSelect(fileutil.Encodings, nil)
+	to.SetSelected(fileutil.UTF8)
+	endings := widget.NewSelect(append([]string{convertUnchanged}, fileutil.LineEndings...), nil)
+	endings.SetSelected(convertUnchanged)
+	bom := widget.NewCheck("", nil)
+
+	items := []*widget.FormItem{
+		widget.NewFormItem("From Encoding", from),
+		widget.NewFormItem("To Encoding", to),
+		widget.NewFormItem("Line Endings", endings),
+		widget.NewFormItem("Byte Order Mark", bom),
+	}
+	title := fmt.Sprintf("Convert %d Files", len(paths))
+	if len(paths) == 1 {
+		title = "Convert " + filepath.Base(paths[0])
+	}
+	dialog.ShowForm(title, "convert", "cancel", items, func(ok bool) {
+		if !ok {
+			return
+		}
+		source := from.Selected
+		if source == convertDetect {
+			source = ""
+		}
+		ending := endings.Selected
+		if ending == convertUnchanged {
+			ending = ""
+		}
+		converted := 0
+		for _, path := range paths {
+			if err := fileutil.ConvertFile(path, source, to.Selected, ending, bom.Checked); err != nil {
+				sys.Toast(fmt.Sprintf("Fail %s on file %s, Convert Terminated", err.Error(), path), sys.ErrorToast)
+				break
+			}
+			converted++
+		}
+		if converted > 0 {
+			sys.Toast(fmt.Sprintf("%d Files Converted", converted), sys.InfoToast)
+		}
+		if done != nil {
+			done()
+		}
+	}, window)
+}
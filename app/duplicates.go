@@ -0,0 +1,255 @@
+package app
+
+/*
+
+  File:    duplicates.go
+  Author:  Bob Shofner
+
+  MIT License - https://opensource.org/license/mit/
+
+  This permission notice shall be included in all copies
+    or substantial portions of the Software.
+
+  Find duplicate files, and trash or hard link them.
+
+*/
+
+import (
+	"fman/fileutil"
+	"fman/sys"
+	"fmt"
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"strings"
+)
+
+var duplicatesCount = 1
+
+// at most this many groups are shown
+var duplicatesShown = 500
+
+type duplicates struct {
+	system  *sys.System
+	roots   []string
+	changed func()
+	win     fyne.Window
+	box     *fyne.Container
+	status  *widget.Label
+	groups  []fileutil.DuplicateGroup
+	checks  [][]*widget.Check
+	cancel  bool
+}
+
+// NewDuplicates scans the roots for duplicate files.
+// changed is called after files are trashed or linked.
+func NewDuplicates(system *sys.System, roots []string, changed func()) {
+	d := &duplicates{
+		system:  system,
+		roots:   roots,
+		changed: changed,
+	}
+	id := fmt.Sprintf("Duplicates(%d)", duplicatesCount)
+	duplicatesCount++
+	d.win = fyne.CurrentApp().NewWindow("Duplicates in " + strings.Join(roots, ", "))
+	openWindows[id] = d.win
+	d.win.SetOnClosed(func() {
+		d.cancel = true
+		delete(openWindows, id)
+	})
+
+	d.box = container.NewVBox()
+	d.status = widget.NewLabel("")
+	stop := widget.NewButtonWithIcon("", theme.MediaStopIcon(), func() {
+		d.cancel = true
+	})
+	allButNewest := widget.NewButton("All But Newest", func() {
+		for _, checks := range d.checks {
+			for i, check := range checks {
+				check.SetChecked(i < len(checks)-1)
+			}
+		}
+	})
+	clearChecks := widget.NewButton("Clear", func() {
+		for _, checks := range d.checks {
+			for _, check := range checks {
+				check.SetChecked(false)
+			}
+		}
+	})
+	trash := widget.NewButtonWithIcon("Trash", theme.DeleteIcon(), func() {
+		d.trashSelected()
+	})
+	link := widget.NewButtonWithIcon("Hard Link", theme.ContentCopyIcon(), func() {
+		d.linkSelected()
+	})
+	dismiss := widget.NewButton(" Dismiss ", func() {
+		d.win.Close()
+	})
+	buttons := container.NewHBox(allButNewest, clearChecks, trash, link, stop, dismiss)
+	content := container.NewBorder(nil, container.NewBorder(nil, nil, d.status, buttons),
+		nil, nil, container.NewVScroll(d.box))
+	d.win.SetContent(content)
+	d.win.Resize(fyne.NewSize(700, 450))
+	d.win.SetFixedSize(false)
+	d.win.Show()
+	d.system.BusyIndicator.Start()
+	d.status.SetText("Scanning ...")
+	go d.scan(stop)
+}
+
+// scan finds the duplicates in the background, then shows them
+func (d *duplicates) scan(stop *widget.Button) {
+	groups, err := fileutil.FindDuplicates(d.roots, func() bool {
+		d.system.BusyIndicator.Refresh()
+		return d.cancel
+	})
+	fyne.Do(func() {
+		d.system.BusyIndicator.Stop()
+		stop.Disable()
+		if err != nil {
+			d.status.SetText(fmt.Sprintf("Scan ended: %s", err))
+			return
+		}
+		d.groups = groups
+		d.show()
+	})
+}
+
+// show the groups, each file has a Check to select it
+func (d *duplicates) show() {
+	d.box.Objects = make([]fyne.CanvasObject, 0)
+	d.checks = make([][]*widget.Check, 0)
+	var wasted int64
+	for n, group := range d.groups {
+		wasted += group.Size * int64(len(group.Files)-1)
+		if n >= duplicatesShown {
+			continue
+		}
+		title := fmt.Sprintf("%d copies of %s  (%s)", len(group.Files),
+			fileutil.PrettyDiskSize(uint64(group.Size)), group.Hash[:12])
+		d.box.Objects = append(d.box.Objects,
+			widget.NewLabelWithStyle(title, fyne.TextAlignLeading, fyne.TextStyle{Bold: true}))
+		checks := make([]*widget.Check, 0, len(group.Files))
+		for _, file := range group.Files {
+			check := widget.NewCheck(fmt.Sprintf("%s   %s", file.Info.ModTime().Format(DefaultDateTimeFormat),
+				file.Path), nil)
+			checks = append(checks, check)
+			d.box.Objects = append(d.box.Objects, check)
+		}
+		d.checks = append(d.checks, checks)
+	}
+	status := fmt.Sprintf("%d groups, %s in duplicates", len(d.groups), fileutil.PrettyDiskSize(uint64(wasted)))
+	if len(d.groups) > duplicatesShown {
+		status += fmt.Sprintf(" (first %d shown)", duplicatesShown)
+	}
+	d.status.SetText(status)
+	d.box.Refresh()
+}
+
+// selected files of each shown group
+func (d *duplicates) selected() (count int, sel [][]bool) {
+	sel = make([][]bool, len(d.checks))
+	for g, checks := range d.checks {
+		sel[g] = make([]bool, len(checks))
+		for i, check := range checks {
+			sel[g][i] = check.Checked
+			if check.Checked {
+				count++
+			}
+		}
+	}
+	return
+}
+
+func (d *duplicates) trashSelected() {
+	count, sel := d.selected()
+	if count < 1 {
+		sys.Toast("No Files(s) Selected", sys.WarnToast)
+		return
+	}
+	// as linking, one copy of each is kept
+	for g, selected := range sel {
+		every := true
+		for _, on := range selected {
+			every = every && on
+		}
+		if every {
+			sys.Toast(fmt.Sprintf("Every copy of %s is selected, keep one", d.groups[g].Files[0].Path), sys.WarnToast)
+			return
+		}
+	}
+	msg := fmt.Sprintf("Move %d duplicate files to the Trash?", count)
+	dialog.ShowConfirm("Trash Duplicates", msg, func(yes bool) {
+		if !yes {
+			return
+		}
+		d.apply(sel, func(_ fileutil.DuplicateGroup, file fileutil.DuplicateFile, _ []bool) error {
+			return fileutil.MoveToTrash(file.Path)
+		})
+	}, d.win)
+}
+
+func (d *duplicates) linkSelected() {
+	count, sel := d.selected()
+	if count < 1 {
+		sys.Toast("No Files(s) Selected", sys.WarnToast)
+		return
+	}
+	msg := fmt.Sprintf("Replace %d duplicate files with hard links to an unselected copy?", count)
+	dialog.ShowConfirm("Link Duplicates", msg, func(yes bool) {
+		if !yes {
+			return
+		}
+		d.apply(sel, func(group fileutil.DuplicateGroup, file fileutil.DuplicateFile, selected []bool) error {
+			// link to the newest copy not selected
+			for i := len(group.Files) - 1; i >= 0; i-- {
+				if !selected[i] {
+					return fileutil.ReplaceWithLink(group, group.Files[i], file)
+				}
+			}
+			return fmt.Errorf("every copy of %s is selected, keep one", file.Path)
+		})
+	}, d.win)
+}
+
+// apply an operation to the selected files, and drop them from their group
+func (d *duplicates) apply(sel [][]bool, op func(fileutil.DuplicateGroup, fileutil.DuplicateFile, []bool) error) {
+	var errs []string
+	done := 0
+	for g := range sel {
+		group := d.groups[g]
+		keep := make([]fileutil.DuplicateFile, 0, len(group.Files))
+		for i, file := range group.Files {
+			if !sel[g][i] {
+				keep = append(keep, file)
+				continue
+			}
+			if err := op(group, file, sel[g]); err != nil {
+				errs = append(errs, err.Error())
+				keep = append(keep, file)
+				continue
+			}
+			done++
+		}
+		d.groups[g].Files = keep
+	}
+	groups := make([]fileutil.DuplicateGroup, 0, len(d.groups))
+	for _, group := range d.groups {
+		if len(group.Files) > 1 {
+			groups = append(groups, group)
+		}
+	}
+	d.groups = groups
+	d.show()
+	if len(errs) > 0 {
+		sys.Toast(fmt.Sprintf("%d failed: %s", len(errs), errs[0]), sys.ErrorToast)
+	} else {
+		sys.Toast(fmt.Sprintf("%d duplicates done", done), sys.InfoToast)
+	}
+	if d.changed != nil {
+		d.changed()
+	}
+}
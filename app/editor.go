@@ -0,0 +1,102 @@
+package app
+
+import (
+	"fman/element"
+	"fman/fileutil"
+	"fman/sys"
+	"fmt"
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"os"
+	"path/filepath"
+)
+
+/*
+
+  File:    editor.go
+  Author:  Bob Shofner
+
+  MIT License - https://opensource.org/license/mit/
+
+  This permission notice shall be included in all copies
+    or substantial portions of the Software.
+
+*/
+/*
+  Description: a window with the built-in text editor of a file
+*/
+
+// larger files are viewed, not edited
+const maxEditSize = 8 * 1024 * 1024
+
+var editorCount = 1
+
+func NewEditor(system *sys.System, path string) {
+	info, err := os.Stat(path)
+	if err != nil {
+		sys.Toast(err.Error(), sys.ErrorToast)
+		return
+	}
+	if info.IsDir() {
+		sys.Toast(fmt.Sprintf("%s is a Directory", filepath.Base(path)), sys.WarnToast)
+		return
+	}
+	if info.Size() > maxEditSize {
+		sys.Toast(fmt.Sprintf("%s is too large to edit, use View", filepath.Base(path)), sys.WarnToast)
+		return
+	}
+	text, format, err := fileutil.ReadText(path)
+	if err != nil {
+		sys.Toast(err.Error(), sys.ErrorToast)
+		return
+	}
+	id := fmt.Sprintf("Editor(%d)", editorCount)
+	editorCount++
+	w := fyne.CurrentApp().NewWindow(id)
+	ed := element.NewTextEditor(w, filepath.Base(path), text, format, nil)
+	ed.OnSave = func(text string, format fileutil.TextFormat) error {
+		return fileutil.WriteText(path, text, format)
+	}
+	ed.OnSaveAs = func() {
+		sel := fileutil.FileSelectFilter{
+			Title:      "Save " + filepath.Base(path) + " As",
+			FileType:   fileutil.File,
+			FileSelect: fileutil.Save,
+		}
+		fileutil.FileSelect(sel, nil, w, func(files []string) {
+			if len(files) == 0 {
+				return
+			}
+			// the editor is of the new file once it is saved
+			previous := path
+			path = files[0]
+			if !ed.Save() {
+				path = previous
+				return
+			}
+			ed.SetName(filepath.Base(path))
+		})
+	}
+	w.SetContent(ed.Content)
+	w.Resize(fyne.NewSize(800, 600))
+	openWindows[id] = w
+	unsavedWindows[id] = ed.Dirty
+	w.SetCloseIntercept(func() {
+		if !ed.Dirty() {
+			w.Close()
+			return
+		}
+		dialog.ShowConfirm("Unsaved Changes", "Discard the changes to "+filepath.Base(path)+"?",
+			func(yes bool) {
+				if yes {
+					w.Close()
+				}
+			}, w)
+	})
+	w.SetOnClosed(func() {
+		delete(openWindows, id)
+		delete(unsavedWindows, id)
+	})
+	w.SetFixedSize(false)
+	w.Show()
+}
@@ -0,0 +1,127 @@
+package app
+
+/*
+
+  File:    extract.go
+  Author:  Bob Shofner
+
+  MIT License - https://opensource.org/license/mit/
+
+  This permission notice shall be included in all copies
+    or substantial portions of the Software.
+
+  Extract an archive, all or the chosen entries, into a folder.
+  Existing files are replaced as a copy does, only by newer entries unless Overwrite ALL.
+
+*/
+
+import (
+	"errors"
+	"fman/fileutil"
+	"fman/sys"
+	"fmt"
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"path/filepath"
+)
+
+// ExtractTo - a dialog to extract an archive into dest, done is called after
+func ExtractTo(window fyne.Window, path, dest string, done func()) {
+	name := filepath.Base(path)
+	entries, err := fileutil.ListArchive(path, maxArchiveEntries, func() bool {
+		return false
+	})
+	if errors.Is(err, fileutil.ErrNotArchive) {
+		sys.Toast(fmt.Sprintf("%s is not a zip, tar or gzip", name), sys.WarnToast)
+		return
+	}
+	if err != nil {
+		sys.Toast(fmt.Sprintf("%s: %s", name, err), sys.ErrorToast)
+		return
+	}
+
+	chosen := make([]bool, len(entries))
+	list := widget.NewList(func() int {
+		return len(entries)
+	}, func() fyne.CanvasObject {
+		return widget.NewCheck("", nil)
+	}, func(id widget.ListItemID, object fyne.CanvasObject) {
+		check := object.(*widget.Check)
+		check.OnChanged = nil
+		check.SetText(entries[id].Name)
+		check.SetChecked(chosen[id])
+		check.OnChanged = func(on bool) {
+			chosen[id] = on
+		}
+	})
+	list.Hide()
+	every := widget.NewCheck("All Entries", func(on bool) {
+		if on {
+			list.Hide()
+		} else {
+			list.Show()
+		}
+	})
+	every.SetChecked(true)
+
+	// as CopyModeSelect
+	latest := widget.NewCheck("Latest ONLY", nil)
+	all := widget.NewCheck("Overwrite ALL", nil)
+	latest.SetChecked(true)
+	latest.OnChanged = func(t bool) {
+		if t {
+			all.SetChecked(false)
+		}
+	}
+	all.OnChanged = func(t bool) {
+		if t {
+			latest.SetChecked(false)
+		}
+	}
+	top := container.NewVBox(widget.NewLabel(fmt.Sprintf("%d entries, to %s", len(entries), dest)),
+		container.NewHBox(every, latest, all))
+	content := container.NewBorder(top, nil, nil, nil, list)
+
+	title := fmt.Sprintf("Extract %s", name)
+	dlg := dialog.NewCustomConfirm(title, "Extract", "Cancel", content, func(ok bool) {
+		if !ok {
+			return
+		}
+		options := fileutil.ExtractOptions{All: all.Checked}
+		options.MaxSize, options.MaxEntries = sys.GetSystem().Settings.ExtractLimits()
+		if !every.Checked {
+			options.Only = make(map[string]bool)
+			for i, entry := range entries {
+				if chosen[i] {
+					options.Only[entry.Name] = true
+				}
+			}
+			if len(options.Only) == 0 {
+				sys.Toast("No Entries Selected", sys.WarnToast)
+				return
+			}
+		}
+		system := sys.GetSystem()
+		system.BusyIndicator.Start()
+		go func() {
+			count, err := fileutil.ExtractArchive(path, dest, options, func() {
+				system.BusyIndicator.Refresh()
+			})
+			fyne.Do(func() {
+				system.BusyIndicator.Stop()
+				if err != nil {
+					sys.Toast(fmt.Sprintf("Fail %s on file %s, Extract Terminated", err, path), sys.ErrorToast)
+				} else {
+					sys.Toast(fmt.Sprintf("%d Files Extracted", count), sys.InfoToast)
+				}
+				if done != nil {
+					done()
+				}
+			})
+		}()
+	}, window)
+	dlg.Resize(fyne.NewSize(600, 450))
+	dlg.Show()
+}
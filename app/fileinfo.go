@@ -17,9 +17,11 @@ package app
 
 import (
 	"errors"
+	"fman/fileutil"
 	"fman/sys"
 	"fmt"
 	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/data/binding"
 	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/widget"
@@ -79,6 +81,19 @@ func FileInfoEdit(window fyne.Window, path string) {
 		Widget: widget.NewLabel(fmt.Sprintf("%s\n%s\n%s\n%s\n%s      ",
 			name, dir, mode, size, dt)),
 	})
+	var meta *fileutil.ImageMetadata
+	if fi.Mode().IsRegular() {
+		meta, _ = fileutil.ReadImageMetadata(path)
+	}
+	if meta != nil {
+		strip := widget.NewButton("Strip Metadata", func() {
+			StripImageMetadata(window, []string{path}, nil)
+		})
+		items = append(items, &widget.FormItem{
+			Text:   "Image:",
+			Widget: container.NewVBox(widget.NewLabel(meta.String()), container.NewHBox(strip)),
+		})
+	}
 	nameV := widget.NewEntryWithData(binding.BindString(&newName))
 	nameV.Wrapping = fyne.TextWrapOff
 	nameV.SetText(name)
@@ -99,9 +114,16 @@ func FileInfoEdit(window fyne.Window, path string) {
 		_, err := time.Parse(DefaultDateTimeFormat, s)
 		return err
 	}
+	var dtW fyne.CanvasObject = dtV
+	if meta != nil && !meta.Taken.IsZero() {
+		taken := widget.NewButton("EXIF Date", func() {
+			dtV.SetText(meta.Taken.Local().Format(DefaultDateTimeFormat))
+		})
+		dtW = container.NewBorder(nil, nil, nil, taken, dtV)
+	}
 	items = append(items, &widget.FormItem{
 		Text:   "Modified:",
-		Widget: dtV,
+		Widget: dtW,
 	})
 
 	dlg := dialog.NewForm(path, "Apply", "Cancel", items,
@@ -0,0 +1,157 @@
+package app
+
+/*
+
+  File:    findFilter.go
+  Author:  Bob Shofner
+
+  MIT License - https://opensource.org/license/mit/
+
+  This permission notice shall be included in all copies
+    or substantial portions of the Software.
+
+  Criteria (other than the name) to select files in the Finder.
+
+*/
+
+import (
+	"errors"
+	"fman/fileutil"
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var findDateFormat = "2006-01-02"
+var findTypes = []string{"Any", "File", "Directory"}
+
+// editFindFilter - a form dialog to set the search filters
+func editFindFilter(window fyne.Window, filter fileutil.FindFilter, cb func(fileutil.FindFilter)) {
+	fileType := widget.NewRadioGroup(findTypes, nil)
+	fileType.Horizontal = true
+	fileType.SetSelected(findTypes[filter.FileType])
+
+	minSize := newNumberEntry(filter.MinSize/1024, "KB")
+	maxSize := newNumberEntry(filter.MaxSize/1024, "KB")
+	after := newDateEntry(filter.After)
+	before := newDateEntry(filter.Before)
+	extensions := widget.NewEntry()
+	extensions.PlaceHolder = ".go, .txt"
+	extensions.SetText(strings.Join(filter.Extensions, ", "))
+	depth := newNumberEntry(int64(filter.MaxDepth), "levels")
+	exclude := widget.NewEntry()
+	exclude.PlaceHolder = ".git, node_modules"
+	exclude.SetText(strings.Join(filter.Exclude, ", "))
+	follow := widget.NewCheck("", nil)
+	follow.SetChecked(filter.FollowLinks)
+
+	items := []*widget.FormItem{
+		widget.NewFormItem("Type", fileType),
+		widget.NewFormItem("Minimum Size", minSize),
+		widget.NewFormItem("Maximum Size", maxSize),
+		widget.NewFormItem("Modified After", after),
+		widget.NewFormItem("Modified Before", before),
+		widget.NewFormItem("Extensions", extensions),
+		widget.NewFormItem("Maximum Depth", depth),
+		widget.NewFormItem("Exclude Folders", exclude),
+		widget.NewFormItem("Follow Links", follow),
+	}
+	dlg := dialog.NewForm("Search Filters", "Apply", "Cancel", items, func(b bool) {
+		if !b {
+			return
+		}
+		f := fileutil.FindFilter{FollowLinks: follow.Checked}
+		for i, t := range findTypes {
+			if t == fileType.Selected {
+				f.FileType = fileutil.FileSelectType(i)
+			}
+		}
+		f.MinSize = parseNumber(minSize.Text) * 1024
+		f.MaxSize = parseNumber(maxSize.Text) * 1024
+		f.After, _ = time.ParseInLocation(findDateFormat, after.Text, time.Local)
+		f.Before, _ = time.ParseInLocation(findDateFormat, before.Text, time.Local)
+		f.Extensions = splitList(extensions.Text)
+		f.MaxDepth = int(parseNumber(depth.Text))
+		f.Exclude = splitList(exclude.Text)
+		cb(f)
+	}, window)
+	dlg.Resize(fyne.NewSize(400, 450))
+	dlg.Show()
+}
+
+func newNumberEntry(n int64, placeHolder string) *widget.Entry {
+	entry := widget.NewEntry()
+	entry.PlaceHolder = placeHolder
+	if n > 0 {
+		entry.SetText(strconv.FormatInt(n, 10))
+	}
+	entry.Validator = func(s string) error {
+		if s == "" {
+			return nil
+		}
+		if _, err := strconv.ParseUint(s, 10, 63); err != nil {
+			return errors.New("number required")
+		}
+		return nil
+	}
+	return entry
+}
+
+func newDateEntry(t time.Time) *widget.Entry {
+	entry := widget.NewEntry()
+	entry.PlaceHolder = "YYYY-MM-DD"
+	if !t.IsZero() {
+		entry.SetText(t.Format(findDateFormat))
+	}
+	entry.Validator = func(s string) error {
+		if s == "" {
+			return nil
+		}
+		_, err := time.Parse(findDateFormat, s)
+		return err
+	}
+	return entry
+}
+
+func parseNumber(s string) int64 {
+	n, _ := strconv.ParseInt(s, 10, 64)
+	return n
+}
+
+// splitList separates a comma separated list, ignoring empty items
+func splitList(s string) (list []string) {
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item != "" {
+			list = append(list, item)
+		}
+	}
+	return
+}
+
+// describeFindFilter summarizes the active filters for the Finder title
+func describeFindFilter(f fileutil.FindFilter) string {
+	var parts []string
+	if f.FileType != fileutil.Any {
+		parts = append(parts, findTypes[f.FileType])
+	}
+	if f.MinSize > 0 || f.MaxSize > 0 {
+		parts = append(parts, "size")
+	}
+	if !f.After.IsZero() || !f.Before.IsZero() {
+		parts = append(parts, "date")
+	}
+	if len(f.Extensions) > 0 {
+		parts = append(parts, strings.Join(f.Extensions, ","))
+	}
+	if f.MaxDepth > 0 {
+		parts = append(parts, "depth "+strconv.Itoa(f.MaxDepth))
+	}
+	if f.FollowLinks {
+		parts = append(parts, "links")
+	}
+	return strings.Join(parts, " ")
+}
@@ -16,6 +16,7 @@ package app
 
 import (
 	"errors"
+	"fman/element/tappable"
 	"fman/fileutil"
 	"fman/sys"
 	"fmt"
@@ -24,8 +25,12 @@ import (
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
 	"io"
+	"io/fs"
 	"regexp"
 	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
 )
 
 type Finder struct {
@@ -39,13 +44,24 @@ type Finder struct {
 	box      *fyne.Container
 	criteria string
 	max      int
+	// content search
+	isRegEx       bool
+	caseSensitive bool
+	maxSize       int64
+	grep          *fileutil.Grepper
+	filter        fileutil.FindFilter
+	// results, fed to a panel by ToPanel
+	found    []string
+	ToPanel  func(parent string, paths []string)
+	searches int // the search shown, results of another are dropped
 }
 
 func NewFinder(system *sys.System, id string, selected func(string)) *Finder {
 	finder := &Finder{
 		system:   system,
 		id:       id,
-		selected: selected}
+		selected: selected,
+		filter:   fileutil.FindFilter{Exclude: fileutil.DefaultExclude}}
 	buildWindow(finder)
 	return finder
 }
@@ -67,12 +83,42 @@ func buildWindow(finder *Finder) {
 		finder.max = int(v)
 		sliderValue.SetText(fmt.Sprintf("%3s", strconv.Itoa(finder.max)))
 	}
+	// the slider is an optional cap on the number of results
+	limit := widget.NewCheck("", func(b bool) {
+		if b {
+			slider.Enable()
+			finder.max = int(slider.Value)
+			sliderValue.SetText(fmt.Sprintf("%3s", strconv.Itoa(finder.max)))
+		} else {
+			slider.Disable()
+			finder.max = 0
+			sliderValue.SetText("all")
+		}
+	})
+	limit.SetChecked(true)
+	filters := widget.NewButtonWithIcon("", theme.ListIcon(), nil)
+	filters.OnTapped = func() {
+		editFindFilter(finder.win, finder.filter, func(f fileutil.FindFilter) {
+			finder.filter = f
+			filters.SetText(describeFindFilter(f))
+		})
+	}
 	stop := widget.NewButtonWithIcon("", theme.MediaStopIcon(), nil)
 	stop.OnTapped = func() {
 		stop.Disable()
 		finder.cancel = true
 	}
 	stop.Disable()
+	toPanel := widget.NewButton(" To Panel ", func() {
+		if finder.ToPanel == nil || len(finder.found) < 1 {
+			return
+		}
+		stop.Disable()
+		finder.cancel = true
+		found := make([]string, len(finder.found))
+		copy(found, finder.found)
+		finder.ToPanel(finder.parent, found)
+	})
 	dismiss := widget.NewButton(" Dismiss ", func() {
 		stop.Disable()
 		finder.cancel = true
@@ -81,35 +127,64 @@ func buildWindow(finder *Finder) {
 	criteria.Validator = func(s string) error {
 		return nil
 	}
-	criteria.ActionItem = widget.NewButtonWithIcon(
-		"", theme.SearchIcon(),
-		func() {
-			if criteria.Text == "" {
-				return
-			}
-			if _, err := regexp.Compile("(?i)" + criteria.Text); err != nil {
-				criteria.SetValidationError(errors.New("invalid"))
-				return
-			}
-			finder.criteria = criteria.Text
-			stop.Enable()
-			find(finder)
-		})
-	criteria.OnSubmitted = func(k string) {
-		if criteria.Text == "" {
+	contains := widget.NewEntry()
+	contains.PlaceHolder = "Containing Text (optional)"
+	isRegEx := widget.NewCheck("reg exp", func(b bool) {
+		finder.isRegEx = b
+	})
+	caseSensitive := widget.NewCheck("case", func(b bool) {
+		finder.caseSensitive = b
+	})
+	maxSize := widget.NewEntry()
+	maxSize.PlaceHolder = "max KB"
+	start := func() {
+		if criteria.Text == "" && contains.Text == "" && describeFindFilter(finder.filter) == "" {
 			return
 		}
-		if _, err := regexp.Compile("(?i)" + k); err != nil {
-			t := fmt.Sprintf("%s : %s", criteria.Text, err.Error())
-			criteria.SetText(t)
+		if _, err := regexp.Compile("(?i)" + criteria.Text); err != nil {
+			criteria.SetValidationError(errors.New("invalid"))
 			return
 		}
-		finder.criteria = k
+		finder.grep = nil
+		if contains.Text != "" {
+			finder.maxSize = 0
+			if kb, err := strconv.ParseInt(maxSize.Text, 10, 64); err == nil && kb > 0 {
+				finder.maxSize = kb * 1024
+			}
+			g, err := fileutil.NewGrepper(contains.Text, finder.isRegEx, finder.caseSensitive, finder.maxSize)
+			if err != nil {
+				contains.SetValidationError(errors.New("invalid"))
+				return
+			}
+			finder.grep = g
+		}
+		finder.criteria = criteria.Text
 		stop.Enable()
 		find(finder)
 	}
-	controls := container.NewHBox(sliderValue, slider, stop, dismiss)
-	params := container.NewBorder(nil, nil, nil, controls, criteria)
+	criteria.ActionItem = widget.NewButtonWithIcon(
+		"", theme.SearchIcon(),
+		func() {
+			start()
+		})
+	criteria.OnSubmitted = func(k string) {
+		if criteria.Text != "" {
+			if _, err := regexp.Compile("(?i)" + k); err != nil {
+				t := fmt.Sprintf("%s : %s", criteria.Text, err.Error())
+				criteria.SetText(t)
+				return
+			}
+		}
+		start()
+	}
+	contains.OnSubmitted = func(_ string) {
+		start()
+	}
+	controls := container.NewHBox(filters, limit, sliderValue, slider, stop, toPanel, dismiss)
+	options := container.NewHBox(isRegEx, caseSensitive, maxSize)
+	params := container.NewVBox(
+		container.NewBorder(nil, nil, nil, controls, criteria),
+		container.NewBorder(nil, nil, nil, options, contains))
 	finder.box = container.NewVBox()
 	finder.scroll = container.NewVScroll(finder.box)
 	results := container.NewStack(finder.scroll)
@@ -122,42 +197,141 @@ func buildWindow(finder *Finder) {
 
 func find(finder *Finder) {
 	finder.cancel = false
+	finder.searches++
 	finder.box.Objects = make([]fyne.CanvasObject, 0)
+	finder.found = make([]string, 0)
 	finder.box.Refresh()
 	rx := regexp.MustCompile("(?i)" + finder.criteria)
 	finder.system.BusyIndicator.Start()
-	go search(finder, rx)
+	go search(finder, rx, finder.searches, finder.max)
 }
 
-func search(finder *Finder, regexp *regexp.Regexp) {
+// search runs in the background, the results are shown (and kept) in the fyne thread
+func search(finder *Finder, regexp *regexp.Regexp, searches, max int) {
 	n := 0
+	full := func() bool {
+		return max > 0 && n >= max
+	}
+	// show adds the items of a path, unless another search has started
+	show := func(path string, items ...findResult) {
+		fyne.Do(func() {
+			if searches != finder.searches {
+				return
+			}
+			if path != "" {
+				finder.found = append(finder.found, path)
+			}
+			for _, item := range items {
+				finder.box.Objects = append(finder.box.Objects,
+					newFindItem(finder, item.display, item.path, item.line))
+			}
+			finder.box.Refresh()
+		})
+	}
+	// the index (when current) replaces walking the directories
+	walk := fileutil.FindWalk
+	var candidate func(path string) bool
+	if idx := fileIndex; idx != nil && idx.Covers(finder.parent) && !finder.filter.FollowLinks {
+		walk = idx.Walk
+		if finder.grep != nil {
+			if text, ok := finder.grep.Literal(); ok {
+				candidate = idx.Candidates(text)
+			}
+		}
+	}
 	// executes until cancel, max, or error
-	fileutil.DirTreeList(finder.parent, func(path string) error {
+	_ = walk(finder.parent, finder.filter, func(path string, info fs.FileInfo) error {
 		if finder.cancel {
 			return io.EOF
 		}
 		finder.system.BusyIndicator.Refresh()
-		if regexp.MatchString(path) {
+		if !regexp.MatchString(path) {
+			return nil
+		}
+		if candidate != nil && !candidate(path) {
+			return nil
+		}
+		if finder.grep == nil {
 			n++
-			finder.box.Objects = append(finder.box.Objects,
-				newFindItem(finder, path))
-			finder.box.Refresh()
-			if n >= finder.max {
+			show(path, findResult{path, path, 0})
+			if full() {
 				return io.EOF
 			}
+			return nil
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		left := 0
+		if max > 0 {
+			left = max - n
+		}
+		hits, _ := finder.grep.Grep(path, left)
+		items := make([]findResult, 0, len(hits))
+		for _, hit := range hits {
+			n++
+			items = append(items,
+				findResult{fmt.Sprintf("%s:%d: %s", path, hit.Line, snippet(hit)), path, hit.Line})
+		}
+		if len(hits) > 0 {
+			show(path, items...)
+		}
+		if full() {
+			return io.EOF
 		}
 		return nil
 	})
-	finder.box.Objects = append(finder.box.Objects,
-		newFindItem(finder, fmt.Sprintf("Finished Searching ... found %d items", n)))
-	finder.system.BusyIndicator.Stop()
-	go finder.box.Refresh()
+	show("", findResult{fmt.Sprintf("Finished Searching ... found %d items", n), "", 0})
+	fyne.Do(func() {
+		finder.system.BusyIndicator.Stop()
+	})
+}
+
+// findResult is a FindItem, made in the background
+type findResult struct {
+	display string
+	path    string
+	line    int
+}
+
+// snippet trims a matching line to the text around the match
+func snippet(hit fileutil.GrepHit) string {
+	text := hit.Text
+	from := hit.Col1 - 30
+	to := hit.Col2 + 50
+	if from < 0 {
+		from = 0
+	}
+	if to > len(text) {
+		to = len(text)
+	}
+	if from > to {
+		from = to
+	}
+	// whole runes
+	for from > 0 && !utf8.RuneStart(text[from]) {
+		from--
+	}
+	for to < len(text) && !utf8.RuneStart(text[to]) {
+		to++
+	}
+	s := strings.TrimSpace(strings.ToValidUTF8(text[from:to], ""))
+	if from > 0 {
+		s = "..." + s
+	}
+	if to < len(text) {
+		s += "..."
+	}
+	return s
 }
 
 type FindItem struct {
 	widget.Label
 	finder   *Finder
+	path     string
+	line     int // content match, 1 based
 	onTapped func(string)
+	lastTime time.Time
 }
 
 var _ fyne.Focusable = (*FindItem)(nil)
@@ -180,17 +354,30 @@ func (t *FindItem) TypedKey(key *fyne.KeyEvent) {
 func (t *FindItem) TypedRune(rune) {
 }
 
-func newFindItem(finder *Finder, display string) *FindItem {
+func newFindItem(finder *Finder, display, path string, line int) *FindItem {
 	fi := &FindItem{}
 	fi.ExtendBaseWidget(fi)
 	fi.SetText(display)
 	fi.finder = finder
+	fi.path = path
+	fi.line = line
 	fi.onTapped = finder.selected
 	return fi
 }
 
+// Tapped goes to the item's place, a double tap on a content match views it.
 func (t *FindItem) Tapped(_ *fyne.PointEvent) {
-	t.onTapped(t.Text)
+	if t.path == "" {
+		return
+	}
+	duration := time.Now().Sub(t.lastTime)
+	if duration <= tappable.TapperDoubleClickTime && t.line > 0 {
+		t.lastTime = time.Time{}
+		NewViewerAtLine(t.finder.system, t.path, t.line)
+		return
+	}
+	t.lastTime = time.Now()
+	t.onTapped(t.path)
 }
 
 // Find - new search criteria
@@ -0,0 +1,120 @@
+package app
+
+import (
+	"encoding/csv"
+	"fman/element"
+	"fman/fileutil"
+	"fman/sys"
+	"fmt"
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/widget"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+/*
+
+  File:    formatViewer.go
+  Author:  Bob Shofner
+
+  MIT License - https://opensource.org/license/mit/
+
+  This permission notice shall be included in all copies
+    or substantial portions of the Software.
+
+*/
+/*
+  Description: a window with a viewer for the format of a file (by extension),
+  a tree for JSON and YAML, a table for CSV and TSV, and formatted Markdown.
+*/
+
+const (
+	jsonFormat     = "json"
+	yamlFormat     = "yaml"
+	csvFormat      = "csv"
+	tsvFormat      = "tsv"
+	markdownFormat = "markdown"
+)
+
+var viewFormats = map[string]string{
+	".json": jsonFormat, ".jsonl": jsonFormat, ".ndjson": jsonFormat, ".geojson": jsonFormat,
+	".yaml": yamlFormat, ".yml": yamlFormat,
+	".csv": csvFormat,
+	".tsv": tsvFormat, ".tab": tsvFormat,
+	".md": markdownFormat, ".markdown": markdownFormat,
+}
+
+// NewFormatViewer views a file in its format. Others, and files that
+// don't parse, are shown in the text viewer.
+func NewFormatViewer(system *sys.System, path string) {
+	name := filepath.Base(path)
+	format := viewFormats[strings.ToLower(filepath.Ext(path))]
+	if format == "" {
+		NewViewer(system, path)
+		return
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		sys.Toast(err.Error(), sys.ErrorToast)
+		return
+	}
+	if info.Size() > maxEditSize {
+		sys.Toast(fmt.Sprintf("%s is too large to format, viewing the text", name), sys.InfoToast)
+		NewViewer(system, path)
+		return
+	}
+	text, _, err := fileutil.ReadText(path)
+	if err != nil {
+		sys.Toast(err.Error(), sys.ErrorToast)
+		return
+	}
+
+	textView := widget.NewButton("TEXT VIEW", func() {
+		NewViewer(system, path)
+	})
+	buttons := []*widget.Button{textView}
+	var content *fyne.Container
+	switch format {
+	case jsonFormat, yamlFormat:
+		var root *element.TreeNode
+		if format == jsonFormat {
+			root, err = element.ParseJSONTree(strings.NewReader(text))
+		} else {
+			root, err = element.ParseYAMLTree(strings.NewReader(text))
+		}
+		if err == nil {
+			content = element.NewTreeViewer(name, root, buttons).Content
+		}
+	case csvFormat, tsvFormat:
+		reader := csv.NewReader(strings.NewReader(text))
+		if format == tsvFormat {
+			reader.Comma = '\t'
+		}
+		reader.FieldsPerRecord = -1
+		reader.LazyQuotes = true
+		var records [][]string
+		if records, err = reader.ReadAll(); err == nil {
+			content = element.NewTableViewer(name, records, buttons).Content
+		}
+	case markdownFormat:
+		content = element.NewMarkdownViewer(name, text, buttons).Content
+	}
+	if err != nil {
+		sys.Toast(fmt.Sprintf("%s: %s, viewing the text", name, err), sys.WarnToast)
+		NewViewer(system, path)
+		return
+	}
+
+	id := fmt.Sprintf("Viewer(%d)", viewerCount)
+	viewerCount++
+	w := fyne.CurrentApp().NewWindow(id)
+	w.SetContent(content)
+	w.Resize(fyne.NewSize(700, 500))
+	openWindows[id] = w
+	w.SetOnClosed(func() {
+		delete(openWindows, id)
+	})
+	w.SetFixedSize(false)
+	w.Show()
+}
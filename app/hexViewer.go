@@ -0,0 +1,84 @@
+package app
+
+import (
+	"fman/element"
+	"fman/fileutil"
+	"fman/sys"
+	"fmt"
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"os"
+	"path/filepath"
+)
+
+/*
+
+  File:    hexViewer.go
+  Author:  Bob Shofner
+
+  MIT License - https://opensource.org/license/mit/
+
+  This permission notice shall be included in all copies
+    or substantial portions of the Software.
+
+*/
+/*
+  Description: a window with a hex viewer / editor of a file
+*/
+
+var hexViewerCount = 1
+
+func NewHexViewer(system *sys.System, path string) {
+	reader, err := os.Open(path)
+	if err != nil {
+		sys.Toast(err.Error(), sys.ErrorToast)
+		return
+	}
+	info, err := reader.Stat()
+	if err != nil {
+		_ = reader.Close()
+		sys.Toast(err.Error(), sys.ErrorToast)
+		return
+	}
+	id := fmt.Sprintf("HexViewer(%d)", hexViewerCount)
+	hexViewerCount++
+	w := fyne.CurrentApp().NewWindow(id)
+	hv := element.NewHexViewer(w, filepath.Base(path), reader, info.Size(), nil)
+	backedUp := false // the backup is of the file as opened
+	hv.OnSave = func(edits map[int64]byte) error {
+		if !backedUp {
+			if err := fileutil.BackupFile(path); err != nil {
+				return err
+			}
+			backedUp = true
+		}
+		err := fileutil.PatchFile(path, edits)
+		if err == nil {
+			sys.Toast(fmt.Sprintf("%d bytes saved, backup is %s", len(edits),
+				filepath.Base(path)+fileutil.BackupSuffix), sys.InfoToast)
+		}
+		return err
+	}
+	w.SetContent(hv.Content)
+	w.Resize(fyne.NewSize(700, 400))
+	openWindows[id] = w
+	unsavedWindows[id] = hv.Edited
+	w.SetCloseIntercept(func() {
+		if !hv.Edited() {
+			w.Close()
+			return
+		}
+		dialog.ShowConfirm("Unsaved Changes", "Discard the changed bytes?", func(yes bool) {
+			if yes {
+				w.Close()
+			}
+		}, w)
+	})
+	w.SetOnClosed(func() {
+		_ = reader.Close()
+		delete(openWindows, id)
+		delete(unsavedWindows, id)
+	})
+	w.SetFixedSize(false)
+	w.Show()
+}
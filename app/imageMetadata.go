@@ -0,0 +1,74 @@
+package app
+
+/*
+
+  File:    imageMetadata.go
+  Author:  Bob Shofner
+
+  MIT License - https://opensource.org/license/mit/
+
+  This permission notice shall be included in all copies
+    or substantial portions of the Software.
+
+  Strip the metadata of images, or set their modified time from their EXIF date.
+
+*/
+
+import (
+	"fman/fileutil"
+	"fman/sys"
+	"fmt"
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"path/filepath"
+)
+
+// StripImageMetadata - confirm, then strip the metadata of images, done is called after
+func StripImageMetadata(window fyne.Window, paths []string, done func()) {
+	message := fmt.Sprintf("Remove the EXIF, XMP, IPTC data and comments of %d images?", len(paths))
+	if len(paths) == 1 {
+		message = fmt.Sprintf("Remove the EXIF, XMP, IPTC data and comments of %s?", filepath.Base(paths[0]))
+	}
+	dialog.ShowConfirm("Strip Image Metadata", message, func(ok bool) {
+		if !ok {
+			return
+		}
+		eachImage(paths, fileutil.StripMetadata, "Stripped", done)
+	}, window)
+}
+
+// SetTimesFromExif - confirm, then set the modified time of images to their EXIF date
+func SetTimesFromExif(window fyne.Window, paths []string, done func()) {
+	message := fmt.Sprintf("Set the modified time of %d images to when they were taken?", len(paths))
+	if len(paths) == 1 {
+		message = fmt.Sprintf("Set the modified time of %s to when it was taken?", filepath.Base(paths[0]))
+	}
+	dialog.ShowConfirm("Modified Time from EXIF", message, func(ok bool) {
+		if !ok {
+			return
+		}
+		eachImage(paths, fileutil.SetTimeFromExif, "Dated", done)
+	}, window)
+}
+
+// eachImage applies change to the images, those that fail are counted, the first reported
+func eachImage(paths []string, change func(path string) error, verb string, done func()) {
+	changed := 0
+	var failed []error
+	for _, path := range paths {
+		if err := change(path); err != nil {
+			failed = append(failed, err)
+			continue
+		}
+		changed++
+	}
+	if len(failed) > 0 {
+		sys.Toast(fmt.Sprintf("%d Images Failed, %s", len(failed), failed[0].Error()), sys.ErrorToast)
+	}
+	if changed > 0 {
+		sys.Toast(fmt.Sprintf("%d Images %s", changed, verb), sys.InfoToast)
+	}
+	if done != nil {
+		done()
+	}
+}
@@ -0,0 +1,41 @@
+package app
+
+/*
+
+  File:    indexer.go
+  Author:  Bob Shofner
+
+  MIT License - https://opensource.org/license/mit/
+
+  This permission notice shall be included in all copies
+    or substantial portions of the Software.
+
+  The optional background file index used by the Finder.
+
+*/
+
+import (
+	"fman/fileutil"
+	"fman/sys"
+	"path/filepath"
+)
+
+var fileIndex *fileutil.FileIndex
+
+// StartIndexer (re)starts indexing the roots set in the Preferences
+func StartIndexer(system *sys.System) {
+	StopIndexer()
+	if len(system.Settings.IndexRoots) < 1 {
+		return
+	}
+	file := filepath.Join(system.Storage, system.AppName+".index")
+	fileIndex = fileutil.NewFileIndex(file, system.Settings.IndexRoots, system.Settings.IndexContent)
+}
+
+// StopIndexer saves the index, called when app is closing
+func StopIndexer() {
+	if fileIndex != nil {
+		fileIndex.Close()
+		fileIndex = nil
+	}
+}
@@ -0,0 +1,216 @@
+package app
+
+/*
+
+  File:    quickView.go
+  Author:  Bob Shofner
+
+  MIT License - https://opensource.org/license/mit/
+
+  This permission notice shall be included in all copies
+    or substantial portions of the Software.
+
+  A preview of a file, shown in place of a panel's files:
+  the head of a text file, an image, the contents of an archive,
+  or a summary (files, size) of a folder.
+
+  Previews are made in the background; showing another ends the one being made.
+
+*/
+
+import (
+	"errors"
+	"fman/fileutil"
+	"fman/sys"
+	"fmt"
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+)
+
+const (
+	quickTextHead    = 16 * 1024 // bytes of text shown
+	quickTextLines   = 300
+	quickArchiveMax  = 1000  // entries listed
+	quickFolderFiles = 50000 // files counted
+)
+
+type QuickView struct {
+	system  *sys.System
+	Content *fyne.Container
+	title   *widget.Label
+	body    *fyne.Container
+	shown   atomic.Int64 // the preview being made
+}
+
+// NewQuickView is an empty preview, Show a file to preview it
+func NewQuickView(system *sys.System) *QuickView {
+	q := &QuickView{
+		system: system,
+		title:  widget.NewLabel("Select a file in the other panel"),
+		body:   container.NewStack(),
+	}
+	q.title.Importance = widget.HighImportance
+	q.title.Truncation = fyne.TextTruncateEllipsis
+	q.Content = container.NewBorder(q.title, nil, nil, nil, q.body)
+	return q
+}
+
+// Show previews a file or folder
+func (q *QuickView) Show(path string) {
+	shown := q.shown.Add(1)
+	q.title.SetText(filepath.Base(path))
+	q.setBody(widget.NewLabel("..."))
+	go func() {
+		preview := q.preview(path, func() bool {
+			return shown != q.shown.Load()
+		})
+		fyne.Do(func() {
+			if shown == q.shown.Load() {
+				q.setBody(preview())
+			}
+		})
+	}()
+}
+
+// Close ends any preview being made
+func (q *QuickView) Close() {
+	q.shown.Add(1)
+}
+
+func (q *QuickView) setBody(object fyne.CanvasObject) {
+	q.body.Objects = []fyne.CanvasObject{object}
+	q.body.Refresh()
+}
+
+// preview reads what is shown (in the background), and is how to show it (in the fyne thread)
+func (q *QuickView) preview(path string, stale func() bool) func() fyne.CanvasObject {
+	info, err := os.Stat(path)
+	if err != nil {
+		return quickMessage(err.Error())
+	}
+	if info.IsDir() {
+		return quickFolder(path, info, stale)
+	}
+	switch sys.GetAssocType(q.system.Settings, info.Name()) {
+	case "image":
+		return quickImage(path)
+	case "zip", "gzip", "tar":
+		return quickArchive(path, stale)
+	}
+	return quickText(path, info)
+}
+
+func quickMessage(message string) func() fyne.CanvasObject {
+	return func() fyne.CanvasObject {
+		label := widget.NewLabel(message)
+		label.Wrapping = fyne.TextWrapWord
+		return container.NewVBox(label)
+	}
+}
+
+// quickFolder counts the files and folders, and their size
+func quickFolder(path string, info fs.FileInfo, stale func() bool) func() fyne.CanvasObject {
+	var files, folders int
+	var size int64
+	tooMany := errors.New("too many files")
+	err := filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || p == path {
+			return nil
+		}
+		if stale() {
+			return io.EOF
+		}
+		if d.IsDir() {
+			folders++
+			return nil
+		}
+		files++
+		if files > quickFolderFiles {
+			return tooMany
+		}
+		if i, e := d.Info(); e == nil {
+			size += i.Size()
+		}
+		return nil
+	})
+	more := ""
+	if errors.Is(err, tooMany) {
+		more = "more than "
+	}
+	return quickMessage(fmt.Sprintf("Folder\n%s%d files, %d folders\n%s\nModified %s",
+		more, files, folders, fileutil.PrettyDiskSize(uint64(size)),
+		info.ModTime().Format(DefaultDateTimeFormat)))
+}
+
+// quickImage is the image, and its metadata
+func quickImage(path string) func() fyne.CanvasObject {
+	img, _, err := fileutil.DecodeImage(path)
+	if err != nil {
+		return quickMessage(err.Error())
+	}
+	meta, _ := fileutil.ReadImageMetadata(path)
+	return func() fyne.CanvasObject {
+		image := canvas.NewImageFromImage(img)
+		image.FillMode = canvas.ImageFillContain
+		image.ScaleMode = canvas.ImageScaleSmooth
+		if meta == nil {
+			return image
+		}
+		return container.NewBorder(nil, widget.NewLabel(meta.String()), nil, nil, image)
+	}
+}
+
+// quickArchive lists the entries of an archive
+func quickArchive(path string, stale func() bool) func() fyne.CanvasObject {
+	entries, err := fileutil.ListArchive(path, quickArchiveMax, stale)
+	if err != nil {
+		return quickMessage(err.Error())
+	}
+	lines := make([]string, 0, len(entries)+1)
+	for _, entry := range entries {
+		lines = append(lines, fmt.Sprintf("%s %10d %s %s", entry.Mode,
+			entry.Size, entry.Modified.Format("01/02/06 15:04"), entry.Name))
+	}
+	if len(entries) == quickArchiveMax {
+		lines = append(lines, fmt.Sprintf("... the first %d entries", quickArchiveMax))
+	}
+	return quickTextGrid(fmt.Sprintf("%d entries", len(entries)), strings.Join(lines, "\n"))
+}
+
+// quickText is the head of a text file, or what is known of another
+func quickText(path string, info fs.FileInfo) func() fyne.CanvasObject {
+	file, err := os.Open(path)
+	if err != nil {
+		return quickMessage(err.Error())
+	}
+	sample := make([]byte, quickTextHead)
+	n, _ := io.ReadFull(file, sample)
+	_ = file.Close()
+	sample = sample[:n]
+	if fileutil.LooksBinary(sample) {
+		return quickMessage(fmt.Sprintf("Binary file\n%s\n%s\nModified %s", info.Mode(),
+			fileutil.PrettyDiskSize(uint64(info.Size())), info.ModTime().Format(DefaultDateTimeFormat)))
+	}
+	text, format := fileutil.DecodeText(sample)
+	lines := strings.Split(text, "\n")
+	if len(lines) > quickTextLines {
+		lines = lines[:quickTextLines]
+	}
+	status := fmt.Sprintf("%s, %s", format.Encoding, fileutil.PrettyDiskSize(uint64(info.Size())))
+	return quickTextGrid(status, strings.Join(lines, "\n"))
+}
+
+func quickTextGrid(status, text string) func() fyne.CanvasObject {
+	return func() fyne.CanvasObject {
+		grid := widget.NewTextGridFromString(strings.ReplaceAll(text, "\t", "    "))
+		return container.NewBorder(nil, widget.NewLabel(status), nil, nil, container.NewScroll(grid))
+	}
+}
@@ -0,0 +1,195 @@
+package app
+
+/*
+
+  File:    resize.go
+  Author:  Bob Shofner
+
+  MIT License - https://opensource.org/license/mit/
+
+  This permission notice shall be included in all copies
+    or substantial portions of the Software.
+
+  Resize and / or convert images into another folder, as a job that can be stopped.
+
+*/
+
+import (
+	"errors"
+	"fman/fileutil"
+	"fman/sys"
+	"fmt"
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"path/filepath"
+	"strconv"
+)
+
+var resizeCount = 1
+
+var resizeMaxSide = "Longest Side (pixels)"
+var resizePercent = "Percent"
+
+// the last used, as the defaults of the next
+var resizeLast = fileutil.ResizeOptions{
+	MaxSide:       1280,
+	Format:        fileutil.FormatSame,
+	EncodeOptions: fileutil.EncodeOptions{Quality: 85, Colors: 256},
+}
+
+type resizeJob struct {
+	win      fyne.Window
+	progress *widget.ProgressBar
+	status   *widget.Label
+	stop     *widget.Button
+	cancel   bool
+}
+
+// ResizeImages - a form dialog to resize / convert images into dir, done is called after
+func ResizeImages(window fyne.Window, paths []string, dir string, done func()) {
+	by := widget.NewSelect([]string{resizeMaxSide, resizePercent}, nil)
+	size := widget.NewEntry()
+	size.Validator = func(s string) error {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 1 || (by.Selected == resizePercent && n > 400) {
+			return errors.New("a size, or percent up to 400")
+		}
+		return nil
+	}
+	by.OnChanged = func(s string) {
+		if s == resizePercent {
+			size.SetText("50")
+		} else {
+			size.SetText(strconv.Itoa(resizeLast.MaxSide))
+		}
+	}
+	if resizeLast.Percent > 0 {
+		by.SetSelected(resizePercent)
+		size.SetText(strconv.Itoa(resizeLast.Percent))
+	} else {
+		by.SetSelected(resizeMaxSide)
+	}
+	format := widget.NewSelect(fileutil.ImageFormats, nil)
+	format.SetSelected(resizeLast.Format)
+	quality := widget.NewSlider(10, 100)
+	quality.Step = 5
+	quality.SetValue(float64(resizeLast.Quality))
+	qualityValue := widget.NewLabel("")
+	quality.OnChanged = func(f float64) {
+		qualityValue.SetText(fmt.Sprintf("%3.0f", f))
+	}
+	quality.OnChanged(quality.Value)
+	colors := widget.NewSelect([]string{"256", "128", "64", "32", "16", "8", "4", "2"}, nil)
+	colors.SetSelected(strconv.Itoa(resizeLast.Colors))
+	overwrite := widget.NewCheck("", nil)
+	overwrite.SetChecked(resizeLast.Overwrite)
+
+	items := []*widget.FormItem{
+		widget.NewFormItem("Resize By", by),
+		widget.NewFormItem("Size", size),
+		widget.NewFormItem("Format", format),
+		widget.NewFormItem("JPEG Quality", container.NewBorder(nil, nil, nil, qualityValue, quality)),
+		widget.NewFormItem("GIF Colors", colors),
+		widget.NewFormItem("Overwrite", overwrite),
+		widget.NewFormItem("To", widget.NewLabel(dir)),
+	}
+	title := fmt.Sprintf("Resize %d Images", len(paths))
+	if len(paths) == 1 {
+		title = "Resize " + filepath.Base(paths[0])
+	}
+	dlg := dialog.NewForm(title, "resize", "cancel", items, func(ok bool) {
+		if !ok {
+			return
+		}
+		n, _ := strconv.Atoi(size.Text)
+		options := fileutil.ResizeOptions{Format: format.Selected, Overwrite: overwrite.Checked}
+		if by.Selected == resizePercent {
+			options.Percent = n
+		} else {
+			options.MaxSide = n
+		}
+		options.Quality = int(quality.Value)
+		options.Colors, _ = strconv.Atoi(colors.Selected)
+		resizeLast = options
+		if resizeLast.MaxSide == 0 {
+			resizeLast.MaxSide = 1280
+		}
+		newResizeJob(paths, dir, options, done)
+	}, window)
+	dlg.Resize(fyne.NewSize(450, dlg.MinSize().Height))
+	dlg.Show()
+}
+
+// newResizeJob shows the progress of resizing, until done or stopped
+func newResizeJob(paths []string, dir string, options fileutil.ResizeOptions, done func()) {
+	id := fmt.Sprintf("Resize(%d)", resizeCount)
+	resizeCount++
+	job := &resizeJob{
+		win:      fyne.CurrentApp().NewWindow(fmt.Sprintf("Resize %d Images to %s", len(paths), dir)),
+		progress: widget.NewProgressBar(),
+		status:   widget.NewLabel(""),
+	}
+	job.progress.Max = float64(len(paths))
+	job.status.Truncation = fyne.TextTruncateEllipsis
+	openWindows[id] = job.win
+	job.win.SetOnClosed(func() {
+		job.cancel = true
+		delete(openWindows, id)
+	})
+	job.stop = widget.NewButtonWithIcon("", theme.MediaStopIcon(), func() {
+		job.cancel = true
+		job.stop.Disable()
+	})
+	dismiss := widget.NewButton(" Dismiss ", func() {
+		job.win.Close()
+	})
+	buttons := container.NewHBox(layout.NewSpacer(), job.stop, dismiss)
+	job.win.SetContent(container.NewVBox(job.progress, job.status, buttons))
+	job.win.Resize(fyne.NewSize(500, job.win.Content().MinSize().Height))
+	job.win.Show()
+	go job.run(paths, dir, options, done)
+}
+
+// run resizes the images, those that fail are counted, the first reported
+func (job *resizeJob) run(paths []string, dir string, options fileutil.ResizeOptions, done func()) {
+	resized := 0
+	var failed []error
+	for i, path := range paths {
+		if job.cancel {
+			break
+		}
+		fyne.Do(func() {
+			job.status.SetText(filepath.Base(path))
+		})
+		if _, err := fileutil.ResizeImage(path, dir, options); err != nil {
+			failed = append(failed, err)
+		} else {
+			resized++
+		}
+		fyne.Do(func() {
+			job.progress.SetValue(float64(i + 1))
+		})
+	}
+	fyne.Do(func() {
+		status := fmt.Sprintf("%d Images Resized", resized)
+		if job.cancel {
+			status += ", Stopped"
+		}
+		if len(failed) > 0 {
+			status += fmt.Sprintf(", %d Failed: %s", len(failed), failed[0])
+			sys.Toast(fmt.Sprintf("%d Images Failed, %s", len(failed), failed[0]), sys.ErrorToast)
+		}
+		job.status.SetText(status)
+		job.stop.Disable()
+		if resized > 0 {
+			sys.Toast(fmt.Sprintf("%d Images Resized", resized), sys.InfoToast)
+		}
+		if done != nil {
+			done()
+		}
+	})
+}
@@ -12,79 +12,419 @@ package app
 
   Display images.
 
+  Images are shown upright (by their EXIF orientation), fitted to the window
+  or zoomed and panned, and may be turned a quarter at a time. A turn can be
+  saved, losslessly, to a JPEG (by its orientation) or PNG. The image's metadata
+  (camera, exposure, GPS, capture date) may be shown beside it.
+
+  Keys: Left / Right (or PageUp / PageDown, Backspace / Space) previous / next,
+  Home / End first / last, + - zoom, 0 fit, 1 actual size, L R rotate,
+  F (or F11) full screen, P (or F5) play, I info, Delete trash, Escape stop.
+
 */
 
 import (
+	"fman/element"
+	"fman/fileutil"
 	"fman/sys"
 	"fmt"
 	"fyne.io/fyne/v2"
-	"fyne.io/fyne/v2/canvas"
 	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/layout"
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
+	"image"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 )
 
 var sliderCount = 1
 
+// autoplay intervals
+var slideIntervals = []string{"1s", "2s", "3s", "5s", "10s", "30s"}
+
+const (
+	slideInterval = 3 * time.Second
+	zoomStep      = 1.25
+)
+
+type slideShow struct {
+	window   fyne.Window
+	images   []string
+	ix       int
+	img      image.Image // upright, before any turns
+	turns    int         // quarter turns clockwise
+	view     *element.ImageView
+	label    *widget.Label
+	status   *widget.Label
+	bars     []fyne.CanvasObject // hidden in full screen
+	save     *widget.Button
+	play     *widget.Button
+	interval time.Duration
+	stop     chan struct{} // of the autoplay, nil if stopped
+	animate  chan struct{} // of a GIF animation, nil if none
+	message  *widget.Label // why an image can't be shown
+	info     *widget.Label // the metadata of the image
+	infoPane fyne.CanvasObject
+}
+
 func NewSlideShow(system *sys.System, path string) {
 
 	// find all the images, based on extension
 	// ix is the one double clicked on
 	// start with it
 	images, ix := buildImageList(system, path)
+	if ix < 0 {
+		images = append([]string{path}, images...)
+		ix = 0
+	}
 
-	image := canvas.NewImageFromFile(images[ix])
-	image.FillMode = canvas.ImageFillContain
-	c := container.New(layout.NewStackLayout(), image)
+	id := fmt.Sprintf("Slider(%d)", sliderCount)
+	sliderCount++
+	show := &slideShow{
+		window:   fyne.CurrentApp().NewWindow(id),
+		images:   images,
+		view:     element.NewImageView(),
+		label:    widget.NewLabel(""),
+		status:   widget.NewLabel(""),
+		message:  widget.NewLabel(""),
+		info:     widget.NewLabel(""),
+		interval: slideInterval,
+	}
+	show.label.Importance = widget.HighImportance
+	show.message.Importance = widget.DangerImportance
+	show.message.Hide()
+	show.infoPane = container.NewVScroll(show.info)
+	show.infoPane.Hide()
+	show.view.OnZoom = func(float32) {
+		show.showStatus()
+	}
+	show.view.OnDoubleTap = show.fullScreen
 
-	label := widget.NewLabel(filepath.Base(path))
-	label.Alignment = fyne.TextAlignCenter
-	previous := widget.NewButton("", func() {
-		ix--
-		ix = showImage(c, images, ix, label)
+	previous := widget.NewButtonWithIcon("", theme.NavigateBackIcon(), func() {
+		show.showImage(show.ix - 1)
 	})
-	previous.SetIcon(theme.NavigateBackIcon())
-	next := widget.NewButton("", func() {
-		ix++
-		ix = showImage(c, images, ix, label)
+	next := widget.NewButtonWithIcon("", theme.NavigateNextIcon(), func() {
+		show.showImage(show.ix + 1)
 	})
-	next.SetIcon(theme.NavigateNextIcon())
-
-	//hb := widget.NewHBox(previous, label, next)
-	hb := container.New(layout.NewBorderLayout(nil, nil, previous, next),
-		previous, next, label)
+	zoomOut := widget.NewButtonWithIcon("", theme.ZoomOutIcon(), func() {
+		show.view.ZoomBy(1 / zoomStep)
+	})
+	zoomIn := widget.NewButtonWithIcon("", theme.ZoomInIcon(), func() {
+		show.view.ZoomBy(zoomStep)
+	})
+	fit := widget.NewButtonWithIcon("", theme.ZoomFitIcon(), func() {
+		show.view.SetZoom(0)
+	})
+	actual := widget.NewButton("1:1", func() {
+		show.view.SetZoom(1)
+	})
+	left := widget.NewButton("ROTATE LEFT", func() {
+		show.rotate(-1)
+	})
+	right := widget.NewButton("ROTATE RIGHT", func() {
+		show.rotate(1)
+	})
+	show.save = widget.NewButtonWithIcon("SAVE ROTATION", theme.DocumentSaveIcon(), show.saveRotation)
+	show.play = widget.NewButtonWithIcon("", theme.MediaPlayIcon(), show.togglePlay)
+	interval := widget.NewSelect(slideIntervals, func(s string) {
+		show.interval, _ = time.ParseDuration(s)
+		if show.stop != nil { // restart at the new pace
+			show.togglePlay()
+			show.togglePlay()
+		}
+		show.window.Canvas().Unfocus()
+	})
+	interval.SetSelected(slideInterval.String())
+	full := widget.NewButtonWithIcon("", theme.ViewFullScreenIcon(), show.fullScreen)
+	trash := widget.NewButtonWithIcon("", theme.DeleteIcon(), show.trash)
+	info := widget.NewButtonWithIcon("", theme.InfoIcon(), show.toggleInfo)
 
-	content := container.New(layout.NewBorderLayout(nil, hb, nil, nil),
-		hb, c)
+	tools := container.NewHBox(zoomOut, zoomIn, fit, actual, left, right, show.save,
+		layout.NewSpacer(), show.play, interval, full, info, trash)
+	bottom := container.NewBorder(nil, nil, previous, next, tools)
+	top := container.NewHBox(show.label, layout.NewSpacer(), show.status)
+	show.bars = []fyne.CanvasObject{top, bottom}
+	center := container.NewStack(show.view, container.NewCenter(show.message))
+	content := container.NewBorder(top, bottom, nil, show.infoPane, center)
 
-	id := fmt.Sprintf("Slider(%d)", sliderCount)
-	sliderCount++
-	w := fyne.CurrentApp().NewWindow(id)
+	w := show.window
+	w.Canvas().SetOnTypedKey(show.typedKey)
+	w.Canvas().SetOnTypedRune(show.typedRune)
 	openWindows[id] = w
+	w.SetOnClosed(func() {
+		show.stopPlay()
+		show.stopAnimation()
+		delete(openWindows, id)
+	})
 	w.SetContent(content)
-	w.Resize(fyne.NewSize(500, 380))
+	w.Resize(fyne.NewSize(900, 650))
 	w.SetFixedSize(false)
+	show.showImage(ix)
 	w.Show()
 }
 
-func showImage(c *fyne.Container, images []string, nx int, label *widget.Label) int {
-	if nx < 0 {
-		nx = len(images) - 1
-	}
-	ix := nx % len(images)
-	image := canvas.NewImageFromFile(images[ix])
-	image.FillMode = canvas.ImageFillContain
-	label.Text = filepath.Base(images[ix])
-	c.Hide()
-	c.Objects = nil
-	c.Add(image)
-	canvas.Refresh(c)
-	label.Refresh()
-	c.Show()
-	return ix
+// showImage shows image nx, wrapping at either end
+func (s *slideShow) showImage(nx int) {
+	if len(s.images) == 0 {
+		return
+	}
+	s.ix = (nx%len(s.images) + len(s.images)) % len(s.images)
+	s.turns = 0
+	s.stopAnimation()
+	path := s.images[s.ix]
+	var img image.Image
+	var animation *fileutil.Animation
+	var delay time.Duration
+	var more bool
+	var err error
+	if strings.EqualFold(filepath.Ext(path), ".gif") {
+		animation, err = fileutil.DecodeAnimation(path)
+	}
+	if animation != nil {
+		img, delay, more = animation.Next()
+	} else if err == nil {
+		img, _, err = fileutil.DecodeImage(path)
+	}
+	s.img = img
+	s.label.SetText(filepath.Base(path))
+	s.view.SetImage(img)
+	if err != nil {
+		s.status.SetText(fmt.Sprintf("%d of %d", s.ix+1, len(s.images)))
+		s.message.SetText(err.Error())
+		s.message.Show()
+	} else {
+		s.message.Hide()
+	}
+	s.save.Disable()
+	s.showInfo()
+	if animation != nil && more {
+		s.animate = make(chan struct{})
+		go s.animation(animation, delay, s.animate)
+	}
+}
+
+// animation shows the frames of a GIF, until the last or stopped
+func (s *slideShow) animation(animation *fileutil.Animation, delay time.Duration, stop chan struct{}) {
+	for more := true; more; {
+		select {
+		case <-stop:
+			return
+		case <-time.After(delay):
+		}
+		fyne.DoAndWait(func() {
+			if s.animate != stop {
+				more = false
+				return
+			}
+			var frame image.Image
+			frame, delay, more = animation.Next()
+			s.view.SetFrame(s.turned(frame))
+		})
+	}
+}
+
+func (s *slideShow) stopAnimation() {
+	if s.animate != nil {
+		close(s.animate)
+		s.animate = nil
+	}
+}
+
+// turned is an image turned as the one shown
+func (s *slideShow) turned(img image.Image) image.Image {
+	if s.turns == 0 {
+		return img
+	}
+	return fileutil.OrientImage(img, fileutil.RotateOrientation(1, s.turns))
+}
+
+func (s *slideShow) toggleInfo() {
+	if s.infoPane.Visible() {
+		s.infoPane.Hide()
+		return
+	}
+	s.infoPane.Show()
+	s.showInfo()
+}
+
+// showInfo shows the metadata of the image, if the info is shown
+func (s *slideShow) showInfo() {
+	if !s.infoPane.Visible() {
+		return
+	}
+	meta, err := fileutil.ReadImageMetadata(s.images[s.ix])
+	if err != nil {
+		s.info.SetText("No metadata")
+		return
+	}
+	lines := make([]string, 0)
+	for _, field := range meta.Fields() {
+		lines = append(lines, field[0]+"\n  "+field[1])
+	}
+	s.info.SetText(strings.Join(lines, "\n"))
+}
+
+func (s *slideShow) showStatus() {
+	if s.img == nil {
+		return
+	}
+	width, height := s.img.Bounds().Dx(), s.img.Bounds().Dy()
+	if s.turns%2 != 0 {
+		width, height = height, width
+	}
+	zoom := fmt.Sprintf("%.0f%%", s.view.Zoom()*100)
+	if s.view.Fitted() {
+		zoom = "fit " + zoom
+	}
+	s.status.SetText(fmt.Sprintf("%d of %d   %d x %d   %s", s.ix+1, len(s.images), width, height, zoom))
+}
+
+// rotate turns the image shown clockwise, quarter turns (negative for anticlockwise)
+func (s *slideShow) rotate(turns int) {
+	if s.img == nil {
+		return
+	}
+	s.turns = ((s.turns+turns)%4 + 4) % 4
+	s.view.SetImage(s.turned(s.img))
+	if s.turns == 0 {
+		s.save.Disable()
+	} else {
+		s.save.Enable()
+	}
+}
+
+func (s *slideShow) saveRotation() {
+	path := s.images[s.ix]
+	if err := fileutil.SaveRotation(path, s.turns); err != nil {
+		sys.Toast(fmt.Sprintf("%s: %s", filepath.Base(path), err), sys.ErrorToast)
+		return
+	}
+	sys.Toast(fmt.Sprintf("%s saved rotated", filepath.Base(path)), sys.InfoToast)
+	s.showImage(s.ix)
+}
+
+func (s *slideShow) fullScreen() {
+	full := !s.window.FullScreen()
+	s.window.SetFullScreen(full)
+	for _, bar := range s.bars {
+		if full {
+			bar.Hide()
+		} else {
+			bar.Show()
+		}
+	}
+}
+
+func (s *slideShow) togglePlay() {
+	if s.stop != nil {
+		s.stopPlay()
+		return
+	}
+	s.stop = make(chan struct{})
+	s.play.SetIcon(theme.MediaPauseIcon())
+	go s.autoplay(s.stop, s.interval)
+}
+
+func (s *slideShow) stopPlay() {
+	if s.stop != nil {
+		close(s.stop)
+		s.stop = nil
+		s.play.SetIcon(theme.MediaPlayIcon())
+	}
+}
+
+// autoplay shows the next image every interval, until stopped
+func (s *slideShow) autoplay(stop chan struct{}, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			fyne.Do(func() {
+				if s.stop == stop {
+					s.showImage(s.ix + 1)
+				}
+			})
+		}
+	}
+}
+
+// trash moves the image shown to the Trash, and shows the next one
+func (s *slideShow) trash() {
+	if len(s.images) == 0 {
+		return
+	}
+	s.stopPlay()
+	path := s.images[s.ix]
+	msg := fmt.Sprintf("Move %s to the Trash?", filepath.Base(path))
+	dialog.ShowConfirm("Trash Image", msg, func(yes bool) {
+		if !yes {
+			return
+		}
+		if err := fileutil.MoveToTrash(path); err != nil {
+			sys.Toast(err.Error(), sys.ErrorToast)
+			return
+		}
+		s.images = append(s.images[:s.ix], s.images[s.ix+1:]...)
+		if len(s.images) == 0 {
+			s.window.Close()
+			return
+		}
+		s.showImage(s.ix)
+	}, s.window)
+}
+
+func (s *slideShow) typedKey(key *fyne.KeyEvent) {
+	switch key.Name {
+	case fyne.KeyLeft, fyne.KeyUp, fyne.KeyPageUp, fyne.KeyBackspace:
+		s.showImage(s.ix - 1)
+	case fyne.KeyRight, fyne.KeyDown, fyne.KeyPageDown, fyne.KeySpace:
+		s.showImage(s.ix + 1)
+	case fyne.KeyHome:
+		s.showImage(0)
+	case fyne.KeyEnd:
+		s.showImage(len(s.images) - 1)
+	case fyne.KeyF11:
+		s.fullScreen()
+	case fyne.KeyF5:
+		s.togglePlay()
+	case fyne.KeyDelete:
+		s.trash()
+	case fyne.KeyEscape:
+		s.stopPlay()
+		if s.window.FullScreen() {
+			s.fullScreen()
+		}
+	}
+}
+
+func (s *slideShow) typedRune(r rune) {
+	switch r {
+	case '+', '=':
+		s.view.ZoomBy(zoomStep)
+	case '-':
+		s.view.ZoomBy(1 / zoomStep)
+	case '0':
+		s.view.SetZoom(0)
+	case '1':
+		s.view.SetZoom(1)
+	case 'l', 'L':
+		s.rotate(-1)
+	case 'r', 'R':
+		s.rotate(1)
+	case 'f', 'F':
+		s.fullScreen()
+	case 'p', 'P':
+		s.togglePlay()
+	case 'i', 'I':
+		s.toggleInfo()
+	}
 }
 
 // find all the images in the folder of the selected file
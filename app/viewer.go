@@ -28,21 +28,37 @@ import (
 var viewerCount = 1
 
 func NewViewer(system *sys.System, path string) {
+	newViewer(system, path)
+}
+
+// NewViewerAtLine opens a viewer scrolled to a (1 based) line.
+func NewViewerAtLine(system *sys.System, path string, line int) {
+	tv := newViewer(system, path)
+	if tv != nil && line > 0 {
+		tv.ShowRow(line - 1)
+	}
+}
+
+func newViewer(system *sys.System, path string) *element.TextViewer {
 	reader, err := os.Open(path)
 	if err != nil {
 		log.Println(err)
-		return
+		return nil
 	}
-	defer func(reader *os.File) {
-		_ = reader.Close()
-	}(reader)
 	tv := element.NewTextViewer(system.MainWindow, filepath.Base(path), reader, nil, 2)
+	tv.SetFollow(path)
 	id := fmt.Sprintf("Viewer(%d)", viewerCount)
 	viewerCount++
 	w := fyne.CurrentApp().NewWindow(id)
 	w.SetContent(tv.Content)
 	w.Resize(fyne.NewSize(600, 400))
 	openWindows[id] = w
+	w.SetOnClosed(func() {
+		tv.Close()
+		_ = reader.Close()
+		delete(openWindows, id)
+	})
 	w.SetFixedSize(false)
 	w.Show()
+	return tv
 }
@@ -1,6 +1,11 @@
 package app
 
-import "fyne.io/fyne/v2"
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"sort"
+	"strings"
+)
 
 /*
 
@@ -14,11 +19,37 @@ import "fyne.io/fyne/v2"
 
 */
 /*
-  Description: insure any new windows are closed on exit.
+  Description: insure any new windows are closed on exit,
+  after asking about those with unsaved changes.
 */
 
 var openWindows = make(map[string]fyne.Window)
 
+// unsavedWindows are the open windows that can have unsaved changes, true if they do
+var unsavedWindows = make(map[string]func() bool)
+
+// ConfirmQuit calls quit, after the user agrees to discard any unsaved changes
+func ConfirmQuit(parent fyne.Window, quit func()) {
+	unsaved := make([]string, 0)
+	for id, dirty := range unsavedWindows {
+		if dirty() {
+			unsaved = append(unsaved, openWindows[id].Title())
+		}
+	}
+	if len(unsaved) == 0 {
+		quit()
+		return
+	}
+	sort.Strings(unsaved)
+	dialog.ShowConfirm("Unsaved Changes",
+		"Discard the unsaved changes in\n"+strings.Join(unsaved, "\n")+"\nand quit?",
+		func(yes bool) {
+			if yes {
+				quit()
+			}
+		}, parent)
+}
+
 func CloseAppWindows() {
 	for _, w := range openWindows {
 		w.Close()
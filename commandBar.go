@@ -59,6 +59,11 @@ func newCommandBar(panelA *control.Panel, panelB *control.Panel) *fyne.Container
 	system.Cline = cline
 	system.Cline.SetIcon(theme.ComputerIcon())
 
+	// duplicate files in the active panel's directory, or both
+	duplicates := widget.NewButton(" Duplicates ", func() {
+		control.PanelDuplicates(panelA, panelB)
+	})
+
 	// label and infinite progress widget
 	active := widget.NewLabel("  Busy: ")
 	system.BusyIndicator = widget.NewProgressBarInfinite()
@@ -80,6 +85,6 @@ func newCommandBar(panelA *control.Panel, panelB *control.Panel) *fyne.Container
 		sys.GetDateTime(system.Settings),
 		sys.GetDescending(system.Settings),
 		widget.NewLabel(" "), font, widget.NewLabel("   "),
-		cline, active, system.BusyIndicator)
+		cline, duplicates, active, system.BusyIndicator)
 	//
 }
@@ -44,6 +44,12 @@ type Panel struct {
 	up      *widget.Label
 	list    *fileutil.CustomList
 	dir     *fileutil.DirectoryEntry
+	// the files as thumbnails, when thumbnails
+	grid       *fileutil.FileGrid
+	thumbnails bool
+	// how the list (or grid) was built
+	filter fileutil.FileSelectFilter
+	action fileutil.FileSelectAction
 	// full (local) path to the parent of the displayed files
 	// "" means nothing showing
 	parent string
@@ -51,6 +57,10 @@ type Panel struct {
 	selected int
 	// secondary selected
 	secondarySelect fileutil.FileEntry
+	// Finder results shown as a flat listing of parent, nil for a real directory
+	virtual []string
+	// a preview of the file selected in the twin, shown in place of the files
+	quickView *app.QuickView
 
 	// controls set or managed by others
 	Refresh *widget.Button
@@ -63,6 +73,8 @@ type Panel struct {
 	Delete  *widget.Button
 	Source  *widget.Button
 	MarkAll *widget.Button
+	Thumbs  *widget.Button
+	Preview *widget.Button
 	Finder  *app.Finder
 	Popup   *widget.PopUpMenu
 	Twin    *Panel
@@ -144,12 +156,23 @@ func NewPanel(id string) (*Panel, *fyne.Container) {
 		default:
 		}
 	})
+	panel.Finder.ToPanel = func(parent string, paths []string) {
+		activePanel = panel
+		panelVirtual(panel, parent, paths)
+	}
 	panel.Copy = widget.NewButton("", func() {
 		activePanel = panel
 		panelCopy(panel)
 	})
 	panel.Copy.SetIcon(theme.ContentCopyIcon())
 
+	formatted := fyne.NewMenuItem("Viewer (JSON, YAML, CSV, Markdown)", func() {
+		if panel.secondarySelect.IsDir() {
+			sys.Toast(fmt.Sprintf("%s is a Directory", panel.secondarySelect.DisplayName()), sys.WarnToast)
+			return
+		}
+		app.NewFormatViewer(sys.GetSystem(), panel.secondarySelect.Name())
+	})
 	view := fyne.NewMenuItem("Text Viewer", func() {
 		if panel.secondarySelect.IsDir() {
 			sys.Toast(fmt.Sprintf("%s is a Directory", panel.secondarySelect.DisplayName()), sys.WarnToast)
@@ -157,6 +180,35 @@ func NewPanel(id string) (*Panel, *fyne.Container) {
 		}
 		executeView(filepath.Join(panel.secondarySelect.Name()))
 	})
+	hex := fyne.NewMenuItem("Hex Viewer", func() {
+		if panel.secondarySelect.IsDir() {
+			sys.Toast(fmt.Sprintf("%s is a Directory", panel.secondarySelect.DisplayName()), sys.WarnToast)
+			return
+		}
+		app.NewHexViewer(sys.GetSystem(), panel.secondarySelect.Name())
+	})
+	convert := fyne.NewMenuItem("Convert Encoding / Line Endings", func() {
+		panelConvert(panel)
+	})
+	strip := fyne.NewMenuItem("Strip Image Metadata", func() {
+		panelStripMetadata(panel)
+	})
+	exifTimes := fyne.NewMenuItem("Modified Time from EXIF", func() {
+		panelExifTimes(panel)
+	})
+	resize := fyne.NewMenuItem("Resize / Convert Images to Other Panel", func() {
+		panelResize(panel)
+	})
+	extract := fyne.NewMenuItem("Extract to...", func() {
+		panelExtract(panel, "")
+	})
+	extractTwin := fyne.NewMenuItem("Extract to Other Panel", func() {
+		if panel.Twin.parent == "" || panel.Twin.parent == "ERROR" {
+			sys.Toast("No Destination Selected", sys.WarnToast)
+			return
+		}
+		panelExtract(panel, panel.Twin.parent)
+	})
 	edit := fyne.NewMenuItem("Text Editor", func() {
 		if panel.secondarySelect.IsDir() {
 			sys.Toast(fmt.Sprintf("%s is a Directory", panel.secondarySelect.DisplayName()), sys.WarnToast)
@@ -164,6 +216,9 @@ func NewPanel(id string) (*Panel, *fyne.Container) {
 		}
 		executeEdit(filepath.Join(panel.secondarySelect.Name()))
 	})
+	compare := fyne.NewMenuItem("Compare Files", func() {
+		panelCompare(panel)
+	})
 	props := fyne.NewMenuItem("Properties Editor", func() {
 		//if panel.secondarySelect.IsDir() {
 		//	sys.Toast(fmt.Sprintf("%s is a Directory", panel.secondarySelect.DisplayName()), sys.WarnToast)
@@ -171,7 +226,8 @@ func NewPanel(id string) (*Panel, *fyne.Container) {
 		//}
 		app.FileInfoEdit(sys.GetSystem().MainWindow, panel.secondarySelect.Name())
 	})
-	menu := fyne.NewMenu("File Options", view, edit, props)
+	menu := fyne.NewMenu("File Options", formatted, view, hex, edit, convert, compare, extract, extractTwin,
+		resize, strip, exifTimes, props)
 	panel.Popup = widget.NewPopUpMenu(menu, panel.canvas)
 
 	panel.Delete = widget.NewButton("", func() {
@@ -199,6 +255,39 @@ func NewPanel(id string) (*Panel, *fyne.Container) {
 		activePanel = panel
 		m := panel.list.Route[fileutil.CtrlA]
 		m(fileutil.CtrlA)
+		panel.listBox.Objects[0].Refresh()
+	}
+
+	// switch between the list and thumbnails
+	panel.Thumbs = widget.NewButton("", nil)
+	panel.Thumbs.SetIcon(theme.GridIcon())
+	panel.Thumbs.OnTapped = func() {
+		activePanel = panel
+		panel.thumbnails = !panel.thumbnails
+		if panel.thumbnails {
+			panel.Thumbs.SetIcon(theme.ListIcon())
+		} else {
+			panel.Thumbs.SetIcon(theme.GridIcon())
+		}
+		if panel.dir != nil && panel.parent != "ERROR" {
+			panel.listBox.Objects[0] = panel.fileView()
+			panel.listBox.Refresh()
+		}
+	}
+
+	// turn the twin into a preview of the file selected here
+	panel.Preview = widget.NewButton("", nil)
+	panel.Preview.SetIcon(theme.VisibilityIcon())
+	panel.Preview.OnTapped = func() {
+		activePanel = panel
+		panel.Twin.togglePreview()
+		if panel.Twin.quickView != nil {
+			panel.Preview.SetIcon(theme.VisibilityOffIcon())
+			panel.Twin.Preview.Disable()
+		} else {
+			panel.Preview.SetIcon(theme.VisibilityIcon())
+			panel.Twin.Preview.Enable()
+		}
 	}
 
 	panel.History = widget.NewSelect(sys.GetSystem().Settings.GetHistory(), func(value string) {
@@ -225,6 +314,7 @@ func (p *Panel) disableOperations() {
 	p.Delete.Disable()
 	p.Source.Disable()
 	p.MarkAll.Disable()
+	p.Thumbs.Disable()
 }
 func (p *Panel) enableOperations() {
 	p.Refresh.Enable()
@@ -235,6 +325,7 @@ func (p *Panel) enableOperations() {
 	p.Delete.Enable()
 	p.Source.Enable()
 	p.MarkAll.Enable()
+	p.Thumbs.Enable()
 }
 
 // UpdateFavorites is called when first loaded to set the saved Favorites
@@ -247,6 +338,14 @@ func (p *Panel) UpdateFavorites() {
 // first line of table - not tappable
 func (p *Panel) showCurrent() {
 	p.enableOperations()
+	if p.quickView != nil {
+		p.current.SetText("Quick View")
+		return
+	}
+	if p.virtual != nil {
+		p.current.SetText(fmt.Sprintf("Found %d in %s", p.dir.Count(), filepath.Base(p.parent)))
+		return
+	}
 	p.current.SetText(fmt.Sprintf("%s", filepath.Base(p.parent)))
 }
 
@@ -285,9 +384,55 @@ func (p *Panel) showError(err error) {
 		return widget.NewIcon(theme.FileIcon())
 	}
 	p.list.UpdateItem = func(id widget.ListItemID, item fyne.CanvasObject) {}
+	p.closeGrid()
 	p.listBox.Objects[0] = p.list
 }
 
+// fileView is the list of files, or the grid of thumbnails, or a preview
+func (p *Panel) fileView() fyne.CanvasObject {
+	p.closeGrid()
+	if p.quickView != nil {
+		return p.quickView.Content
+	}
+	if !p.thumbnails {
+		return p.list
+	}
+	p.grid = fileutil.NewFileGrid(p.dir, p.filter, p.action, func(name string) bool {
+		return sys.GetAssocType(sys.GetSystem().Settings, name) == "image"
+	})
+	return p.grid
+}
+
+// togglePreview shows a preview of the twin's selected file, or the files again
+func (p *Panel) togglePreview() {
+	if p.quickView != nil {
+		p.quickView.Close()
+		p.quickView = nil
+	} else {
+		p.quickView = app.NewQuickView(sys.GetSystem())
+	}
+	switch {
+	case p.parent != "" && p.parent != "ERROR":
+		p.showCurrent()
+		p.listBox.Objects[0] = p.fileView()
+	case p.quickView != nil:
+		p.current.SetText("Quick View")
+		p.listBox.Objects[0] = p.quickView.Content
+	default:
+		p.showEmpty()
+		p.listBox.Objects[0] = p.list
+	}
+	p.listBox.Refresh()
+}
+
+// closeGrid stops any thumbnailing
+func (p *Panel) closeGrid() {
+	if p.grid != nil {
+		p.grid.Close()
+		p.grid = nil
+	}
+}
+
 // ///////////////////////////
 
 func buildItems(panel *Panel, newPlace string) {
@@ -312,6 +457,9 @@ func buildNewItems(panel *Panel, newPlace string) {
 		OnClick: func(e fileutil.FileEntry) {
 			activePanel = panel
 			panel.selected = e.Index()
+			if panel.Twin.quickView != nil {
+				panel.Twin.quickView.Show(e.Name())
+			}
 		},
 		OnSecondaryClick: func(entry fileutil.FileEntry, event *fyne.PointEvent) {
 			activePanel = panel
@@ -335,16 +483,22 @@ func buildNewItems(panel *Panel, newPlace string) {
 			}
 		},
 	}
-	var err error
-	panel.list, panel.dir, err = fileutil.NewFileList(newPlace, fs, fa,
-		nil)
-	if err != nil {
-		panel.showError(err)
-		return
+	if panel.virtual != nil {
+		panel.list, panel.dir = fileutil.NewVirtualFileList(newPlace, panel.virtual, fs, fa, nil)
+	} else {
+		var err error
+		panel.list, panel.dir, err = fileutil.NewFileList(newPlace, fs, fa,
+			nil)
+		if err != nil {
+			panel.showError(err)
+			return
+		}
 	}
 	panel.parent = newPlace
+	panel.filter = fs
+	panel.action = fa
 	panel.showCurrent()
 	panel.showPrevious()
-	panel.listBox.Objects[0] = panel.list
+	panel.listBox.Objects[0] = panel.fileView()
 	panel.listBox.Refresh()
 }
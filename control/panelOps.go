@@ -21,6 +21,8 @@ import (
 	"fman/sys"
 	"fmt"
 	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
 	"os"
 	"path/filepath"
 	"strings"
@@ -65,25 +67,29 @@ func PanelNew(panel *Panel) {
 				sys.GetSystem().BusyIndicator.Stop()
 			}()
 
+			// links (a dangling one too) are archived, not followed
 			files := make([]string, 0)
+			dirs := make([]string, 0)
 			for _, file := range selected {
 				p := filepath.Join(panel.Twin.parent, file.DisplayName())
-				fi, _ := os.Stat(p)
-				if !fi.IsDir() {
-					files = append(files, p)
+				fi, err := os.Lstat(p)
+				if err != nil {
+					sys.Toast(fmt.Sprintf("Fail %s on file %s, Compress Terminated", err, p), sys.ErrorToast)
+					return
 				}
-			}
-			for _, file := range selected {
-				p := filepath.Join(panel.Twin.parent, file.DisplayName())
-				fi, _ := os.Stat(p)
 				if fi.IsDir() {
-					// collect ALL full file names
-					fileutil.DirTreeList(p, func(s string) error {
-						files = append(files, s)
-						return nil
-					})
+					dirs = append(dirs, p)
+				} else {
+					files = append(files, p)
 				}
 			}
+			for _, p := range dirs {
+				// collect ALL full file names
+				fileutil.DirTreeList(p, func(s string) error {
+					files = append(files, s)
+					return nil
+				})
+			}
 			_ = len(files)
 			fext := filepath.Ext(path)
 			// log.Printf("fext %s, ext is %s, count is %d, path is %s ", fext, ext, nfiles, path)
@@ -145,11 +151,23 @@ func panelFind(panel *Panel) {
 	app.Find(panel.Finder, panel.parent)
 }
 
+// panelVirtual shows the Finder results (below parent) as the panel's listing
+func panelVirtual(panel *Panel, parent string, paths []string) {
+	panel.virtual = paths
+	panel.selected = -1
+	sys.GetSystem().Dir = parent
+	buildNewItems(panel, parent)
+}
+
 // Place and Copy are called from the Panel's select widgets and buttons
 func panelPlace(panel *Panel, h string) {
 	if h == "* TEMP *" {
 		h = sys.GetSystem().TempDir
 	}
+	if panel.virtual != nil { // leaving the Finder results
+		panel.virtual = nil
+		panel.parent = ""
+	}
 	panel.History.Options = sys.Remove(panel.History.Options, h)
 	panel.History.Options = append([]string{h}, panel.History.Options...)
 	if len(panel.History.Options) > 10 {
@@ -201,21 +219,222 @@ func panelCopy(panel *Panel) {
 			})
 		}, &sys.GetSystem().MainWindow)
 }
+
+// PanelDuplicates finds duplicate files below the directory of the active panel, or of both
+func PanelDuplicates(panelA, panelB *Panel) {
+	active := activePanel
+	if active == nil {
+		active = panelA
+	}
+	both := "Both Panels"
+	this := "This Panel  " + active.parent
+	choice := widget.NewRadioGroup([]string{this, both}, nil)
+	choice.Required = true
+	choice.SetSelected(this)
+	items := []*widget.FormItem{widget.NewFormItem("Search", choice)}
+	dialog.ShowForm("Find Duplicates", "Find", "Cancel", items, func(ok bool) {
+		if !ok {
+			return
+		}
+		panels := []*Panel{active}
+		if choice.Selected == both {
+			panels = []*Panel{panelA, panelB}
+		}
+		panelDuplicates(panels, func() {
+			PanelRefresh(panelA)
+			PanelRefresh(panelB)
+		})
+	}, sys.GetSystem().MainWindow)
+}
+
+// panelDuplicates finds duplicate files below the directories of the panels
+func panelDuplicates(panels []*Panel, changed func()) {
+	roots := make([]string, 0)
+	for _, p := range panels {
+		if p.parent == "" || p.parent == "ERROR" {
+			continue
+		}
+		// a panel inside the other's tree adds nothing
+		inside := false
+		for i, root := range roots {
+			if fileutil.InsideDir(p.parent, root) {
+				inside = true
+			} else if fileutil.InsideDir(root, p.parent) {
+				roots[i] = p.parent
+				inside = true
+			}
+		}
+		if !inside {
+			roots = append(roots, p.parent)
+		}
+	}
+	if len(roots) < 1 {
+		sys.Toast("Select a Place", sys.WarnToast)
+		return
+	}
+	app.NewDuplicates(sys.GetSystem(), roots, changed)
+}
+
 func executeView(path string) {
-	app.NewViewer(sys.GetSystem(), path)
+	switch sys.GetAssocType(sys.GetSystem().Settings, path) {
+	case "zip", "gzip", "tar": // the table of contents
+		app.NewArchiveViewer(sys.GetSystem(), path)
+	default:
+		app.NewViewer(sys.GetSystem(), path)
+	}
 }
+
+// executeEdit uses the built-in editor, unless an external one is preferred
 func executeEdit(path string) {
+	if !sys.GetSystem().Settings.ExternalEdit {
+		app.NewEditor(sys.GetSystem(), path)
+		return
+	}
 	cmd := sys.GetSystem().Settings.GetEditCommand(path)
 	if cmd != nil {
 		err := cmd.Start()
 		if err != nil {
-			msg := fmt.Sprintf("Edit Error. %s", err)
+			msg := fmt.Sprintf("Edit Error. %s, using the built-in editor", err)
 			n := fyne.NewNotification(sys.GetSystem().AppName, msg)
 			sys.GetSystem().App.SendNotification(n)
 			sys.Toast(msg, sys.ErrorToast)
+			app.NewEditor(sys.GetSystem(), path)
 		}
 	}
 }
+
+// selectedPaths are the selected files, or the file right clicked, none if a directory
+func selectedPaths(panel *Panel) []string {
+	paths := make([]string, 0)
+	for _, s := range panel.dir.GetSelected() {
+		if !s.IsDir() {
+			paths = append(paths, filepath.Join(panel.parent, s.DisplayName()))
+		}
+	}
+	if len(paths) < 1 {
+		if panel.secondarySelect.IsDir() {
+			sys.Toast(fmt.Sprintf("%s is a Directory", panel.secondarySelect.DisplayName()), sys.WarnToast)
+			return nil
+		}
+		paths = append(paths, panel.secondarySelect.Name())
+	}
+	return paths
+}
+
+// panelConvert converts the selected files, or the file right clicked
+func panelConvert(panel *Panel) {
+	paths := selectedPaths(panel)
+	if len(paths) < 1 {
+		return
+	}
+	app.ConvertText(sys.GetSystem().MainWindow, paths, func() {
+		PanelRefresh(panel)
+	})
+}
+
+// panelStripMetadata strips the metadata of the selected images, or the one right clicked
+func panelStripMetadata(panel *Panel) {
+	paths := selectedPaths(panel)
+	if len(paths) < 1 {
+		return
+	}
+	app.StripImageMetadata(sys.GetSystem().MainWindow, paths, func() {
+		PanelRefresh(panel)
+	})
+}
+
+// panelExifTimes dates the selected images, or the one right clicked, by their EXIF
+func panelExifTimes(panel *Panel) {
+	paths := selectedPaths(panel)
+	if len(paths) < 1 {
+		return
+	}
+	app.SetTimesFromExif(sys.GetSystem().MainWindow, paths, func() {
+		PanelRefresh(panel)
+	})
+}
+
+// panelResize resizes / converts the selected images, or the one right clicked, into the twin panel
+func panelResize(panel *Panel) {
+	if panel.Twin.parent == "" {
+		sys.Toast("No Destination Selected", sys.WarnToast)
+		return
+	}
+	images := make([]string, 0)
+	for _, path := range selectedPaths(panel) {
+		if sys.GetAssocType(sys.GetSystem().Settings, filepath.Base(path)) == "image" {
+			images = append(images, path)
+		}
+	}
+	if len(images) < 1 {
+		sys.Toast("No Images Selected", sys.WarnToast)
+		return
+	}
+	app.ResizeImages(sys.GetSystem().MainWindow, images, panel.Twin.parent, func() {
+		PanelRefresh(panel.Twin)
+	})
+}
+
+var extractSel = fileutil.FileSelectFilter{
+	Title:      "Extract to Folder",
+	FileType:   fileutil.Dir,
+	FileSelect: fileutil.Open,
+	Multiple:   false,
+	Hidden:     ""}
+
+// panelExtract extracts the archive right clicked into dest, a chosen folder if ""
+func panelExtract(panel *Panel, dest string) {
+	path := panel.secondarySelect.Name()
+	switch sys.GetAssocType(sys.GetSystem().Settings, path) {
+	case "zip", "gzip", "tar":
+	default:
+		sys.Toast(fmt.Sprintf("%s is not a zip, tar or gzip", panel.secondarySelect.DisplayName()), sys.WarnToast)
+		return
+	}
+	refresh := func() {
+		for _, p := range []*Panel{panel, panel.Twin} {
+			if p.parent != "" {
+				PanelRefresh(p)
+			}
+		}
+	}
+	if dest != "" {
+		app.ExtractTo(sys.GetSystem().MainWindow, path, dest, refresh)
+		return
+	}
+	fileutil.FileSelect(extractSel, nil, sys.GetSystem().MainWindow, func(dirs []string) {
+		if len(dirs) > 0 {
+			app.ExtractTo(sys.GetSystem().MainWindow, path, dirs[0], refresh)
+		}
+	})
+}
+
+// panelCompare compares two files, selected in this panel, or one in each panel
+func panelCompare(panel *Panel) {
+	selectedFiles := func(p *Panel) []string {
+		paths := make([]string, 0)
+		for _, s := range p.dir.GetSelected() {
+			if !s.IsDir() {
+				paths = append(paths, filepath.Join(p.parent, s.DisplayName()))
+			}
+		}
+		return paths
+	}
+	paths := selectedFiles(panel)
+	if len(paths) < 2 && panel.Twin != nil {
+		if len(paths) < 1 && !panel.secondarySelect.IsDir() {
+			paths = append(paths, panel.secondarySelect.Name())
+		}
+		if twin := selectedFiles(panel.Twin); len(twin) == 1 {
+			paths = append(paths, twin[0])
+		}
+	}
+	if len(paths) != 2 {
+		sys.Toast("Select 2 Files in a Panel, or 1 in Each", sys.WarnToast)
+		return
+	}
+	app.NewCompare(sys.GetSystem(), paths[0], paths[1])
+}
 func panelDelete(panel *Panel) {
 	selected := panel.dir.GetSelected()
 	if len(selected) < 1 {
@@ -248,39 +467,16 @@ func panelAction(panel *Panel, path string) {
 	case "image":
 		app.NewSlideShow(sys.GetSystem(), path)
 		return
-	case "zip": // unzip
-		dest := filepath.Join(sys.GetSystem().TempDir,
-			strings.Replace(filepath.Base(path), ".", "_", -1))
-		z, _ := fileutil.NewUnZipper(path)
-		ez := z.Extract(dest, func() {
-			sys.GetSystem().BusyIndicator.Refresh()
-		})
-		if ez != nil {
-			sys.Toast(fmt.Sprintf("Fail %s on file %s, UNZIP Terminated", ez.Error(), path), sys.ErrorToast)
-		}
-		panelPlace(panel, dest)
-		return
-	case "gzip": // ungzip
-		dest := filepath.Join(sys.GetSystem().TempDir,
-			strings.Replace(filepath.Base(path), ".", "_", -1))
-		z, _ := fileutil.NewUnZGipper(path)
-		_, ez := z.Extract(dest, func() {
-			sys.GetSystem().BusyIndicator.Refresh()
-		})
-		if ez != nil {
-			sys.Toast(fmt.Sprintf("Fail %s on file %s, UNGZIP Terminated", ez.Error(), path), sys.ErrorToast)
-		}
-		panelPlace(panel, dest)
-		return
-	case "tar":
+	case "zip", "gzip", "tar": // extract into the temporary folder
 		dest := filepath.Join(sys.GetSystem().TempDir,
 			strings.Replace(filepath.Base(path), ".", "_", -1))
-		z, _ := fileutil.NewUnTar(path)
-		_, ez := z.Extract(dest, func() {
+		var options fileutil.ExtractOptions
+		options.MaxSize, options.MaxEntries = sys.GetSystem().Settings.ExtractLimits()
+		_, ez := fileutil.ExtractArchive(path, dest, options, func() {
 			sys.GetSystem().BusyIndicator.Refresh()
 		})
 		if ez != nil {
-			sys.Toast(fmt.Sprintf("Fail %s on file %s, UNTAR Terminated", ez.Error(), path), sys.ErrorToast)
+			sys.Toast(fmt.Sprintf("Fail %s on file %s, Extract Terminated", ez.Error(), path), sys.ErrorToast)
 		}
 		panelPlace(panel, dest)
 		return
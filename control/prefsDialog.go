@@ -16,12 +16,14 @@ package control
 */
 
 import (
+	"fman/app"
 	"fman/fileutil"
 	"fman/sys"
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/widget"
 	"log"
-	_ "strings"
+	"strconv"
+	"strings"
 )
 
 var prefsWindow fyne.Window
@@ -61,6 +63,21 @@ func newPrefsDialog(system *sys.System, panelA *Panel, panelB *Panel) {
 		})
 	}
 	browser.SetText(system.Settings.Browser)
+	indexRoots := widget.NewEntry()
+	indexRoots.PlaceHolder = "folders to index (comma separated)"
+	indexRoots.Text = strings.Join(system.Settings.IndexRoots, ", ")
+	indexContent := widget.NewCheck("", func(bool) {
+	})
+	indexContent.SetChecked(system.Settings.IndexContent)
+	extractMB := widget.NewEntry()
+	extractMB.PlaceHolder = "MB, 0 for no limit"
+	extractMB.Text = strconv.FormatInt(system.Settings.ExtractMB, 10)
+	extractEntries := widget.NewEntry()
+	extractEntries.PlaceHolder = "entries, 0 for no limit"
+	extractEntries.Text = strconv.Itoa(system.Settings.ExtractEntries)
+	externalEdit := widget.NewCheck(strings.Join(system.Settings.Edit, " "), func(bool) {
+	})
+	externalEdit.SetChecked(system.Settings.ExternalEdit)
 	form := &widget.Form{
 		Items: []*widget.FormItem{},
 		OnSubmit: func() { // handle form submission
@@ -68,7 +85,29 @@ func newPrefsDialog(system *sys.System, panelA *Panel, panelB *Panel) {
 			sys.GetSystem().Settings.SetHiddenFiles(hiddenFiles.Text)
 			sys.GetSystem().Settings.SetHidden(hidden.Checked)
 			sys.GetSystem().Settings.SetBrowser(browser.Text)
-			err := sys.SavePrefs(system.Settings)
+			sys.GetSystem().Settings.SetExternalEdit(externalEdit.Checked)
+			mb, err := strconv.ParseInt(strings.TrimSpace(extractMB.Text), 10, 64)
+			if err != nil || mb < 0 {
+				mb = system.Settings.ExtractMB
+			}
+			entries, err := strconv.Atoi(strings.TrimSpace(extractEntries.Text))
+			if err != nil || entries < 0 {
+				entries = system.Settings.ExtractEntries
+			}
+			sys.GetSystem().Settings.SetExtractLimits(mb, entries)
+			roots := make([]string, 0)
+			for _, root := range strings.Split(indexRoots.Text, ",") {
+				if root = strings.TrimSpace(root); root != "" {
+					roots = append(roots, root)
+				}
+			}
+			if strings.Join(roots, ",") != strings.Join(system.Settings.IndexRoots, ",") ||
+				indexContent.Checked != system.Settings.IndexContent {
+				sys.GetSystem().Settings.SetIndexRoots(roots)
+				sys.GetSystem().Settings.SetIndexContent(indexContent.Checked)
+				app.StartIndexer(system)
+			}
+			err = sys.SavePrefs(system.Settings)
 			if err != nil {
 				log.Printf("Save Settings FAILED: %v\n", err)
 			}
@@ -122,6 +161,8 @@ func newPrefsDialog(system *sys.System, panelA *Panel, panelB *Panel) {
 	form.Append("", spacer)
 	// preferred browser
 	form.Append("Browser", browser)
+	// edit with the command in fman.json, instead of the built-in editor
+	form.Append("External Editor", externalEdit)
 	// format for Date and Time
 	form.Append("Date & Time Format", dateFormat)
 	// ignore "hidden" files
@@ -131,6 +172,12 @@ func newPrefsDialog(system *sys.System, panelA *Panel, panelB *Panel) {
 	// list of Mounts
 	form.Append("Favorites", favorites)
 	form.Append("Remove", remove)
+	// background file index for the Finder
+	form.Append("Index Folders", indexRoots)
+	form.Append("Index Contents", indexContent)
+	// the most an archive may extract, against archive bombs
+	form.Append("Extract Limit (MB)", extractMB)
+	form.Append("Extract Entries", extractEntries)
 
 	form.Append("", spacer)
 	form.Append("", spacer)
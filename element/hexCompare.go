@@ -0,0 +1,295 @@
+package element
+
+/*
+
+  File:    hexCompare.go
+  Author:  Bob Shofner
+
+  MIT License - https://opensource.org/license/mit/
+
+  This permission notice shall be included in all copies
+    or substantial portions of the Software.
+*/
+/*
+  Description: two files in hex side by side, scrolled together,
+  with the bytes that differ highlighted. The compare (of whole files)
+  is done by the owner, and given to SetResult.
+*/
+
+import (
+	"fman/fileutil"
+	"fmt"
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"io"
+	"sort"
+)
+
+type HexCompare struct {
+	Content    *fyne.Container
+	readers    [2]io.ReaderAt
+	sizes      [2]int64
+	result     *fileutil.ByteCompare
+	current    int // of result.Diffs, -1 for none
+	pages      [2]*textPage
+	bar        *widget.Slider
+	summary    *widget.Label
+	status     *widget.Label
+	top        int64 // first row shown
+	settingBar bool
+}
+
+func NewHexCompare(names [2]string, readers [2]io.ReaderAt, sizes [2]int64,
+	buttons []*widget.Button) *HexCompare {
+
+	compare := &HexCompare{
+		readers: readers,
+		sizes:   sizes,
+		current: -1,
+		summary: widget.NewLabel("comparing ..."),
+		status:  widget.NewLabel(""),
+	}
+
+	var titles [2]fyne.CanvasObject
+	for side := range compare.pages {
+		title := widget.NewLabel(fmt.Sprintf("%s (%d bytes)", names[side], sizes[side]))
+		title.Importance = widget.HighImportance
+		titles[side] = title
+		page := newTextPage()
+		page.OnResize = func(_, _ int) {
+			compare.setTop(compare.top)
+		}
+		page.OnScroll = func(rows, _ int) {
+			compare.setTop(compare.top + int64(rows))
+		}
+		page.OnKey = compare.typedKey
+		compare.pages[side] = page
+	}
+
+	previous := widget.NewButtonWithIcon("", theme.MoveUpIcon(), func() {
+		compare.nextDiff(-1)
+	})
+	next := widget.NewButtonWithIcon("", theme.MoveDownIcon(), func() {
+		compare.nextDiff(1)
+	})
+	buttonBar := container.NewHBox(previous, next, compare.status)
+
+	// add in any user buttons
+	if len(buttons) > 0 {
+		buttonBar.Objects = append(buttonBar.Objects, layout.NewSpacer())
+		for _, button := range buttons {
+			buttonBar.Objects = append(buttonBar.Objects, button)
+		}
+	}
+
+	compare.bar = widget.NewSlider(0, 1)
+	compare.bar.Orientation = widget.Vertical
+	compare.bar.Step = 1
+	compare.bar.OnChanged = func(value float64) {
+		if !compare.settingBar {
+			compare.setTop(int64(compare.bar.Max - value))
+		}
+	}
+
+	top := container.NewVBox(compare.summary, container.NewGridWithColumns(2, titles[0], titles[1]))
+	pages := container.NewGridWithColumns(2, compare.pages[0], compare.pages[1])
+	compare.Content = container.NewBorder(top, buttonBar, nil, compare.bar, pages)
+	return compare
+}
+
+// SetResult shows the compare, and the first difference
+func (c *HexCompare) SetResult(result *fileutil.ByteCompare, err error) {
+	if err != nil {
+		c.summary.SetText("compare failed: " + err.Error())
+		return
+	}
+	c.result = result
+	c.current = -1
+	switch {
+	case result.Identical():
+		c.summary.SetText("the files are identical")
+	case result.Differing == 0:
+		c.summary.SetText(fmt.Sprintf("the bytes are the same, up to the end of the shorter file at 0x%x (%d)",
+			result.First(), result.First()))
+	default:
+		more := ""
+		if result.More {
+			more = fmt.Sprintf(" (the first %d listed)", len(result.Diffs))
+		}
+		sizes := ""
+		if result.Sizes[0] != result.Sizes[1] {
+			sizes = ", and the sizes differ"
+		}
+		c.summary.SetText(fmt.Sprintf("%d bytes differ in %d places%s, the first at 0x%x (%d)%s",
+			result.Differing, len(result.Diffs), more, result.First(), result.First(), sizes))
+	}
+	c.showDiff(0)
+}
+
+func (c *HexCompare) size() int64 {
+	return max(c.sizes[0], c.sizes[1])
+}
+
+func (c *HexCompare) lastTop() int64 {
+	rows := (c.size() + hexRowBytes - 1) / hexRowBytes
+	return max(rows-int64(c.pages[0].rows), 0)
+}
+
+func (c *HexCompare) setTop(top int64) {
+	last := c.lastTop()
+	c.top = max(min(top, last), 0)
+	c.settingBar = true
+	c.bar.Max = max(float64(last), 1) // a slider needs a range
+	c.bar.SetValue(c.bar.Max - float64(c.top))
+	c.settingBar = false
+	c.render()
+}
+
+// nextDiff steps forward (1) or back (-1) through the differences
+func (c *HexCompare) nextDiff(step int) {
+	if c.result == nil || len(c.result.Diffs) == 0 {
+		return
+	}
+	if c.current < 0 {
+		// from the top of the page
+		offset := c.top * hexRowBytes
+		n := sort.Search(len(c.result.Diffs), func(i int) bool {
+			return c.result.Diffs[i].Offset >= offset
+		})
+		if step < 0 {
+			n--
+		}
+		c.showDiff(n)
+		return
+	}
+	c.showDiff(c.current + step)
+}
+
+// showDiff scrolls a difference into view, and makes it current
+func (c *HexCompare) showDiff(n int) {
+	if c.result != nil && n >= 0 && n < len(c.result.Diffs) {
+		c.current = n
+		row := c.result.Diffs[n].Offset / hexRowBytes
+		if rows := int64(max(c.pages[0].rows, 1)); row < c.top || row >= c.top+rows {
+			c.top = row - rows/3
+		}
+	} else if c.result != nil && len(c.result.Diffs) == 0 && c.result.First() >= 0 {
+		c.top = c.result.First() / hexRowBytes
+	}
+	c.setTop(c.top)
+	c.showStatus()
+}
+
+func (c *HexCompare) showStatus() {
+	if c.result == nil || c.current < 0 {
+		c.status.SetText("")
+		return
+	}
+	d := c.result.Diffs[c.current]
+	c.status.SetText(fmt.Sprintf("difference %d of %d: %d bytes at 0x%x (%d)",
+		c.current+1, len(c.result.Diffs), d.Length, d.Offset, d.Offset))
+}
+
+func (c *HexCompare) typedKey(key *fyne.KeyEvent) {
+	page := int64(max(c.pages[0].rows-1, 1))
+	switch key.Name {
+	case fyne.KeyUp:
+		c.setTop(c.top - 1)
+	case fyne.KeyDown:
+		c.setTop(c.top + 1)
+	case fyne.KeyPageUp:
+		c.setTop(c.top - page)
+	case fyne.KeyPageDown:
+		c.setTop(c.top + page)
+	case fyne.KeyHome:
+		c.setTop(0)
+	case fyne.KeyEnd:
+		c.setTop(c.lastTop())
+	case fyne.KeyF3, fyne.KeyReturn, fyne.KeyEnter:
+		c.nextDiff(1)
+	case fyne.KeyF2:
+		c.nextDiff(-1)
+	}
+}
+
+// render fills both grids with the same rows, bytes that differ
+// (or are missing from the other file) in the error color.
+func (c *HexCompare) render() {
+	rows := c.pages[0].rows
+	if rows < 1 || c.pages[1].rows < 1 {
+		return // not laid out yet
+	}
+	var bufs [2][]byte
+	for side, reader := range c.readers {
+		buf := make([]byte, rows*hexRowBytes)
+		n, _ := reader.ReadAt(buf, c.top*hexRowBytes)
+		bufs[side] = buf[:n]
+	}
+	var current *fileutil.ByteDiff
+	if c.result != nil && c.current >= 0 {
+		current = &c.result.Diffs[c.current]
+	}
+
+	reverse := &widget.CustomTextGridStyle{
+		TextStyle: fyne.TextStyle{Monospace: true},
+		BGColor:   theme.Color(theme.ColorNameForeground),
+		FGColor:   theme.Color(theme.ColorNameBackground),
+	}
+	differ := &widget.CustomTextGridStyle{
+		TextStyle: fyne.TextStyle{Monospace: true, Bold: true},
+		FGColor:   theme.Color(theme.ColorNameError),
+	}
+	dim := &widget.CustomTextGridStyle{
+		TextStyle: fyne.TextStyle{Monospace: true},
+		FGColor:   theme.Color(theme.ColorNameDisabled),
+	}
+	width := hexOffsetWidth(c.size())
+	for side, page := range c.pages {
+		buf, other := bufs[side], bufs[1-side]
+		grid := make([]widget.TextGridRow, 0, rows)
+		for r := 0; r < rows && r*hexRowBytes < max(len(buf), len(other)); r++ {
+			offset := (c.top + int64(r)) * hexRowBytes
+			line := []rune(fmt.Sprintf("%0*x", width, offset))
+			cells := make([]widget.TextGridCell, asciiColumn(width, hexRowBytes))
+			for col := range cells {
+				cells[col] = widget.TextGridCell{Rune: ' '}
+				if col < len(line) {
+					cells[col] = widget.TextGridCell{Rune: line[col], Style: dim}
+				}
+			}
+			for i := 0; i < hexRowBytes && r*hexRowBytes+i < len(buf); i++ {
+				at := r*hexRowBytes + i
+				b := buf[at]
+				digits := fmt.Sprintf("%02x", b)
+				col := hexColumn(width, i)
+				cells[col].Rune = rune(digits[0])
+				cells[col+1].Rune = rune(digits[1])
+				ch := '.'
+				if b >= 0x20 && b < 0x7f {
+					ch = rune(b)
+				}
+				cells[asciiColumn(width, i)].Rune = ch
+
+				var style widget.TextGridStyle
+				if at >= len(other) || other[at] != b {
+					style = differ
+					if current != nil && offset+int64(i) >= current.Offset &&
+						offset+int64(i) < current.Offset+current.Length {
+						style = reverse
+					}
+				}
+				if style != nil {
+					cells[col].Style = style
+					cells[col+1].Style = style
+					cells[asciiColumn(width, i)].Style = style
+				}
+			}
+			grid = append(grid, widget.TextGridRow{Cells: cells})
+		}
+		page.grid.Rows = grid
+		page.grid.Refresh()
+	}
+}
@@ -0,0 +1,496 @@
+package element
+
+/*
+
+  File:    hexViewer.go
+  Author:  Bob Shofner
+
+  MIT License - https://opensource.org/license/mit/
+
+  This permission notice shall be included in all copies
+    or substantial portions of the Software.
+*/
+/*
+  Description: hex / ASCII viewer and editor.
+  16 bytes per row, read a page at a time. Edits overwrite bytes (the
+  size never changes), and are kept until saved by the owner.
+*/
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const hexRowBytes = 16
+
+type HexViewer struct {
+	Content    *fyne.Container
+	OnSave     func(edits map[int64]byte) error // required to edit
+	name       string
+	window     fyne.Window
+	page       *textPage
+	bar        *widget.Slider
+	status     *widget.Label
+	save       *widget.Button
+	reader     io.ReaderAt
+	size       int64
+	top        int64 // first row shown
+	cursor     int64
+	lowNibble  bool // the next hex digit typed is the low half
+	ascii      bool // typing goes to the ASCII column
+	editing    bool
+	edits      map[int64]byte
+	settingBar bool
+	find       []byte // last search
+}
+
+func NewHexViewer(window fyne.Window, name string, reader io.ReaderAt, size int64,
+	buttons []*widget.Button) *HexViewer {
+
+	viewer := &HexViewer{
+		name:   name,
+		window: window,
+		page:   newTextPage(),
+		status: widget.NewLabel(""),
+		reader: reader,
+		size:   size,
+		edits:  make(map[int64]byte),
+	}
+
+	title := widget.NewLabel(name)
+	title.Importance = widget.HighImportance
+	title.Alignment = fyne.TextAlignTrailing
+
+	edit := widget.NewCheck("edit", func(on bool) {
+		viewer.editing = on
+		viewer.render()
+	})
+	goTo := widget.NewButton("GOTO...", func() {
+		viewer.getOffset()
+	})
+	find := widget.NewButtonWithIcon("SEARCH...", theme.SearchIcon(), func() {
+		viewer.getSearchParameters()
+	})
+	next := widget.NewButtonWithIcon("", theme.MoveDownIcon(), func() {
+		viewer.searchNext()
+	})
+	viewer.save = widget.NewButtonWithIcon("SAVE", theme.DocumentSaveIcon(), func() {
+		viewer.saveEdits()
+	})
+	viewer.save.Disable()
+	buttonBar := container.NewHBox(edit, goTo, find, next, viewer.save, viewer.status)
+
+	// add in any user buttons
+	if len(buttons) > 0 {
+		buttonBar.Objects = append(buttonBar.Objects, layout.NewSpacer())
+		for _, button := range buttons {
+			buttonBar.Objects = append(buttonBar.Objects, button)
+		}
+	}
+
+	viewer.bar = widget.NewSlider(0, 1)
+	viewer.bar.Orientation = widget.Vertical
+	viewer.bar.Step = 1
+	viewer.bar.OnChanged = func(value float64) {
+		if !viewer.settingBar {
+			viewer.setTop(int64(viewer.bar.Max - value))
+		}
+	}
+	viewer.page.OnResize = func(_, _ int) {
+		viewer.setTop(viewer.top)
+	}
+	viewer.page.OnScroll = func(rows, _ int) {
+		viewer.setTop(viewer.top + int64(rows))
+	}
+	viewer.page.OnKey = viewer.typedKey
+	viewer.page.OnRune = viewer.typedRune
+	viewer.page.OnTap = viewer.tapped
+
+	viewer.Content = container.NewBorder(title, buttonBar, nil, viewer.bar, viewer.page)
+	viewer.showStatus()
+	return viewer
+}
+
+// Edited reports unsaved changes
+func (v *HexViewer) Edited() bool {
+	return len(v.edits) > 0
+}
+
+// readAt reads the file, with any edits applied
+func (v *HexViewer) readAt(buf []byte, offset int64) int {
+	return readEdited(v.reader, v.edits, buf, offset)
+}
+
+func readEdited(reader io.ReaderAt, edits map[int64]byte, buf []byte, offset int64) int {
+	n, _ := reader.ReadAt(buf, offset)
+	for i := 0; i < n; i++ {
+		if b, ok := edits[offset+int64(i)]; ok {
+			buf[i] = b
+		}
+	}
+	return n
+}
+
+func (v *HexViewer) rowCount() int64 {
+	return (v.size + hexRowBytes - 1) / hexRowBytes
+}
+
+// offsetWidth is the number of hex digits shown for an offset
+func (v *HexViewer) offsetWidth() int {
+	return hexOffsetWidth(v.size)
+}
+
+func (v *HexViewer) hexColumn(i int) int {
+	return hexColumn(v.offsetWidth(), i)
+}
+
+func (v *HexViewer) asciiColumn(i int) int {
+	return asciiColumn(v.offsetWidth(), i)
+}
+
+// hexOffsetWidth is the number of hex digits shown for offsets within size
+func hexOffsetWidth(size int64) int {
+	if size > 0xffffffff {
+		return 12
+	}
+	return 8
+}
+
+// hexColumn is the cell of byte i of a row in the hex column
+func hexColumn(width, i int) int {
+	col := width + 2 + i*3
+	if i >= hexRowBytes/2 {
+		col++
+	}
+	return col
+}
+
+// asciiColumn is the cell of byte i of a row in the ASCII column
+func asciiColumn(width, i int) int {
+	return hexColumn(width, hexRowBytes-1) + 4 + i
+}
+
+func (v *HexViewer) lastTop() int64 {
+	last := v.rowCount() - int64(v.page.rows)
+	if last < 0 {
+		last = 0
+	}
+	return last
+}
+
+func (v *HexViewer) setTop(top int64) {
+	last := v.lastTop()
+	if top > last {
+		top = last
+	}
+	if top < 0 {
+		top = 0
+	}
+	v.top = top
+	v.settingBar = true
+	v.bar.Max = float64(last)
+	if v.bar.Max < 1 {
+		v.bar.Max = 1 // a slider needs a range
+	}
+	v.bar.SetValue(v.bar.Max - float64(top))
+	v.settingBar = false
+	v.render()
+}
+
+// setCursor moves the cursor, scrolling to keep it visible
+func (v *HexViewer) setCursor(offset int64) {
+	if offset >= v.size {
+		offset = v.size - 1
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	v.cursor = offset
+	v.lowNibble = false
+	row := offset / hexRowBytes
+	top := v.top
+	if row < top {
+		top = row
+	} else if rows := int64(v.page.rows); rows > 0 && row >= top+rows {
+		top = row - rows + 1
+	}
+	v.setTop(top)
+	v.showStatus()
+}
+
+func (v *HexViewer) showStatus() {
+	status := fmt.Sprintf("offset 0x%x (%d) of %d", v.cursor, v.cursor, v.size)
+	if len(v.edits) > 0 {
+		status += fmt.Sprintf(", %d bytes changed", len(v.edits))
+		v.save.Enable()
+	} else {
+		v.save.Disable()
+	}
+	v.status.SetText(status)
+}
+
+// render fills the grid with the visible rows
+func (v *HexViewer) render() {
+	rows := v.page.rows
+	if rows < 1 {
+		return // not laid out yet
+	}
+	buf := make([]byte, rows*hexRowBytes)
+	n := v.readAt(buf, v.top*hexRowBytes)
+	buf = buf[:n]
+
+	reverse := &widget.CustomTextGridStyle{
+		TextStyle: fyne.TextStyle{Monospace: true},
+		BGColor:   theme.Color(theme.ColorNameForeground),
+		FGColor:   theme.Color(theme.ColorNameBackground),
+	}
+	changed := &widget.CustomTextGridStyle{
+		TextStyle: fyne.TextStyle{Monospace: true, Bold: true},
+		FGColor:   theme.Color(theme.ColorNamePrimary),
+	}
+	dim := &widget.CustomTextGridStyle{
+		TextStyle: fyne.TextStyle{Monospace: true},
+		FGColor:   theme.Color(theme.ColorNameDisabled),
+	}
+	width := v.offsetWidth()
+	grid := make([]widget.TextGridRow, 0, rows)
+	for r := 0; r*hexRowBytes < len(buf); r++ {
+		offset := (v.top + int64(r)) * hexRowBytes
+		line := []rune(fmt.Sprintf("%0*x", width, offset))
+		cells := make([]widget.TextGridCell, v.asciiColumn(hexRowBytes))
+		for c := range cells {
+			cells[c] = widget.TextGridCell{Rune: ' '}
+			if c < len(line) {
+				cells[c] = widget.TextGridCell{Rune: line[c], Style: dim}
+			}
+		}
+		for i := 0; i < hexRowBytes && r*hexRowBytes+i < len(buf); i++ {
+			b := buf[r*hexRowBytes+i]
+			digits := fmt.Sprintf("%02x", b)
+			col := v.hexColumn(i)
+			cells[col].Rune = rune(digits[0])
+			cells[col+1].Rune = rune(digits[1])
+			ch := '.'
+			if b >= 0x20 && b < 0x7f {
+				ch = rune(b)
+			}
+			cells[v.asciiColumn(i)].Rune = ch
+
+			var style widget.TextGridStyle
+			if _, ok := v.edits[offset+int64(i)]; ok {
+				style = changed
+			}
+			if offset+int64(i) == v.cursor {
+				style = reverse
+			}
+			if style != nil {
+				cells[col].Style = style
+				cells[col+1].Style = style
+				cells[v.asciiColumn(i)].Style = style
+			}
+		}
+		grid = append(grid, widget.TextGridRow{Cells: cells})
+	}
+	v.page.grid.Rows = grid
+	v.page.grid.Refresh()
+}
+
+func (v *HexViewer) typedKey(key *fyne.KeyEvent) {
+	page := int64(v.page.rows-1) * hexRowBytes
+	if page < hexRowBytes {
+		page = hexRowBytes
+	}
+	switch key.Name {
+	case fyne.KeyUp:
+		v.setCursor(v.cursor - hexRowBytes)
+	case fyne.KeyDown:
+		v.setCursor(v.cursor + hexRowBytes)
+	case fyne.KeyLeft:
+		v.setCursor(v.cursor - 1)
+	case fyne.KeyRight:
+		v.setCursor(v.cursor + 1)
+	case fyne.KeyPageUp:
+		v.setCursor(v.cursor - page)
+	case fyne.KeyPageDown:
+		v.setCursor(v.cursor + page)
+	case fyne.KeyHome:
+		v.setCursor(0)
+	case fyne.KeyEnd:
+		v.setCursor(v.size - 1)
+	case fyne.KeyTab:
+		v.ascii = !v.ascii
+		v.lowNibble = false
+	}
+}
+
+// typedRune overwrites the byte at the cursor, in edit mode
+func (v *HexViewer) typedRune(r rune) {
+	if !v.editing || v.size < 1 {
+		return
+	}
+	buf := make([]byte, 1)
+	v.readAt(buf, v.cursor)
+	if v.ascii {
+		if r < 0x20 || r >= 0x7f {
+			return
+		}
+		v.setByte(byte(r))
+		v.setCursor(v.cursor + 1)
+		return
+	}
+	digit, err := strconv.ParseUint(string(r), 16, 8)
+	if err != nil {
+		return
+	}
+	if v.lowNibble {
+		v.setByte(buf[0]&0xf0 | byte(digit))
+		v.setCursor(v.cursor + 1)
+		return
+	}
+	v.setByte(buf[0]&0x0f | byte(digit)<<4)
+	v.lowNibble = true
+	v.render()
+}
+
+// setByte records an edit at the cursor, forgetting edits back to the original
+func (v *HexViewer) setByte(b byte) {
+	original := make([]byte, 1)
+	_, _ = v.reader.ReadAt(original, v.cursor)
+	if original[0] == b {
+		delete(v.edits, v.cursor)
+	} else {
+		v.edits[v.cursor] = b
+	}
+	v.showStatus()
+}
+
+// tapped moves the cursor to a byte in either column
+func (v *HexViewer) tapped(row, col int) {
+	for i := 0; i < hexRowBytes; i++ {
+		hexCol := v.hexColumn(i)
+		if col == hexCol || col == hexCol+1 || col == v.asciiColumn(i) {
+			v.ascii = col == v.asciiColumn(i)
+			v.setCursor((v.top+int64(row))*hexRowBytes + int64(i))
+			return
+		}
+	}
+}
+
+func (v *HexViewer) saveEdits() {
+	if v.OnSave == nil || len(v.edits) < 1 {
+		return
+	}
+	msg := fmt.Sprintf("Write %d changed bytes to %s?\nThe original is kept as a backup.", len(v.edits), v.name)
+	dialog.ShowConfirm("Save Changes", msg, func(yes bool) {
+		if !yes {
+			return
+		}
+		if err := v.OnSave(v.edits); err != nil {
+			dialog.ShowError(err, v.window)
+			return
+		}
+		v.edits = make(map[int64]byte)
+		v.showStatus()
+		v.render()
+	}, v.window)
+}
+
+// getOffset - a form dialog for an offset to go to (decimal, or 0x hex)
+func (v *HexViewer) getOffset() {
+	entry := widget.NewEntry()
+	entry.PlaceHolder = "1234 or 0x4d2"
+	entry.Validator = func(str string) error {
+		if _, err := strconv.ParseInt(strings.TrimSpace(str), 0, 64); err != nil {
+			return errors.New("not a decimal or 0x hex offset")
+		}
+		return nil
+	}
+	items := []*widget.FormItem{widget.NewFormItem("Offset", entry)}
+	dialog.ShowForm("Go To Offset in "+v.name, "go", "cancel", items, func(b bool) {
+		if b {
+			offset, _ := strconv.ParseInt(strings.TrimSpace(entry.Text), 0, 64)
+			v.setCursor(offset)
+		}
+	}, v.window)
+}
+
+// getSearchParameters - a form dialog for a string or hex bytes to find
+func (v *HexViewer) getSearchParameters() {
+	entry := widget.NewEntry()
+	entry.PlaceHolder = "text, or hex bytes: de ad be ef"
+	isHex := widget.NewCheck("", nil)
+	entry.Validator = func(str string) error {
+		_, err := searchBytes(str, isHex.Checked)
+		return err
+	}
+	items := []*widget.FormItem{
+		widget.NewFormItem("Find", entry),
+		widget.NewFormItem("Hex Bytes?", isHex),
+	}
+	dialog.ShowForm("Search "+v.name, "search", "cancel", items, func(b bool) {
+		if b {
+			v.find, _ = searchBytes(entry.Text, isHex.Checked)
+			v.searchNext()
+		}
+	}, v.window)
+}
+
+// searchBytes converts the search text to bytes
+func searchBytes(text string, isHex bool) ([]byte, error) {
+	if !isHex {
+		if text == "" {
+			return nil, errors.New("nothing to find")
+		}
+		return []byte(text), nil
+	}
+	digits := strings.Join(strings.Fields(text), "")
+	digits = strings.ReplaceAll(digits, "0x", "")
+	find, err := hex.DecodeString(digits)
+	if err != nil || len(find) == 0 {
+		return nil, errors.New("hex bytes are pairs of digits, as 0d 0a")
+	}
+	return find, nil
+}
+
+// searchNext finds the search bytes after the cursor
+func (v *HexViewer) searchNext() {
+	if len(v.find) == 0 {
+		v.getSearchParameters()
+		return
+	}
+	find := v.find
+	start := v.cursor + 1
+	edits := make(map[int64]byte, len(v.edits)) // the search runs while editing continues
+	for offset, b := range v.edits {
+		edits[offset] = b
+	}
+	go func() {
+		block := make([]byte, 1024*1024+len(find))
+		for offset := start; offset < v.size; offset += int64(len(block) - len(find)) {
+			n := readEdited(v.reader, edits, block, offset)
+			if i := bytes.Index(block[:n], find); i >= 0 {
+				fyne.Do(func() {
+					v.setCursor(offset + int64(i))
+				})
+				return
+			}
+			if n < len(block) {
+				break
+			}
+		}
+		fyne.Do(func() {
+			dialog.ShowInformation("Not Found", fmt.Sprintf("after offset 0x%x", start-1), v.window)
+		})
+	}()
+}
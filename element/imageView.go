@@ -0,0 +1,209 @@
+package element
+
+/*
+
+  File:    imageView.go
+  Author:  Bob Shofner
+
+  MIT License - https://opensource.org/license/mit/
+
+  This permission notice shall be included in all copies
+    or substantial portions of the Software.
+*/
+/*
+  Description: an image fitted to the view, or zoomed in a scroll,
+  panned by dragging (or the scroll bars and wheel).
+*/
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+	"image"
+)
+
+const (
+	minZoom = 0.02
+	maxZoom = 32
+)
+
+type ImageView struct {
+	widget.BaseWidget
+	OnDoubleTap func()
+	OnZoom      func(zoom float32) // the zoom shown, 1 is the actual pixels
+	pane        *imagePane
+	scroll      *container.Scroll
+	pixels      fyne.Size // of the image
+	zoom        float32   // 0 fits the image to the view
+}
+
+func NewImageView() *ImageView {
+	view := &ImageView{}
+	view.pane = &imagePane{view: view, image: canvas.NewImageFromImage(nil)}
+	view.pane.ExtendBaseWidget(view.pane)
+	view.scroll = container.NewScroll(view.pane)
+	view.ExtendBaseWidget(view)
+	return view
+}
+
+func (v *ImageView) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(v.scroll)
+}
+
+func (v *ImageView) Resize(size fyne.Size) {
+	v.BaseWidget.Resize(size)
+	if v.zoom == 0 {
+		v.zoomed()
+	}
+}
+
+// SetImage shows an image (nil for none), fitted to the view
+func (v *ImageView) SetImage(img image.Image) {
+	v.pane.image.Image = img
+	v.pixels = fyne.Size{}
+	if img != nil {
+		v.pixels = fyne.NewSize(float32(img.Bounds().Dx()), float32(img.Bounds().Dy()))
+	}
+	v.zoom = 0
+	v.scroll.ScrollToOffset(fyne.Position{})
+	v.pane.image.Refresh()
+	v.zoomed()
+}
+
+// SetFrame replaces the image with another of the same size (the next frame
+// of an animation), keeping the zoom
+func (v *ImageView) SetFrame(img image.Image) {
+	v.pane.image.Image = img
+	v.pane.image.Refresh()
+}
+
+// Fitted is whether the image is fitted to the view
+func (v *ImageView) Fitted() bool {
+	return v.zoom == 0
+}
+
+// Zoom is the zoom shown, 1 being the actual pixels
+func (v *ImageView) Zoom() float32 {
+	if v.zoom != 0 {
+		return v.zoom
+	}
+	if v.pixels.Width == 0 || v.pixels.Height == 0 {
+		return 1
+	}
+	size := v.Size()
+	scale := v.scale()
+	return min(size.Width/v.pixels.Width, size.Height/v.pixels.Height) * scale
+}
+
+// SetZoom zooms about the center of the view, 0 fits the image to the view
+func (v *ImageView) SetZoom(zoom float32) {
+	if zoom != 0 {
+		zoom = max(min(zoom, maxZoom), minZoom)
+	}
+	size := v.scroll.Size()
+	content := v.pane.Size()
+	// the point of the image at the center, as a fraction
+	cx, cy := float32(0.5), float32(0.5)
+	if content.Width > 0 && content.Height > 0 {
+		cx = (v.scroll.Offset.X + size.Width/2) / content.Width
+		cy = (v.scroll.Offset.Y + size.Height/2) / content.Height
+	}
+	v.zoom = zoom
+	v.scroll.Refresh()
+	content = v.pane.Size()
+	v.scroll.ScrollToOffset(fyne.NewPos(cx*content.Width-size.Width/2, cy*content.Height-size.Height/2))
+	v.zoomed()
+}
+
+// ZoomBy changes the zoom by a factor
+func (v *ImageView) ZoomBy(factor float32) {
+	v.SetZoom(v.Zoom() * factor)
+}
+
+func (v *ImageView) zoomed() {
+	if v.OnZoom != nil {
+		v.OnZoom(v.Zoom())
+	}
+}
+
+// scale is the pixels of the canvas per unit
+func (v *ImageView) scale() float32 {
+	if c := fyne.CurrentApp().Driver().CanvasForObject(v); c != nil && c.Scale() > 0 {
+		return c.Scale()
+	}
+	return 1
+}
+
+// imagePane is the content of the scroll, at least the size of the view
+type imagePane struct {
+	widget.BaseWidget
+	view  *ImageView
+	image *canvas.Image
+}
+
+func (p *imagePane) CreateRenderer() fyne.WidgetRenderer {
+	return &imagePaneRenderer{pane: p}
+}
+
+// MinSize is the zoomed image, so the scroll can pan over it
+func (p *imagePane) MinSize() fyne.Size {
+	if p.view.zoom == 0 {
+		return fyne.NewSize(1, 1)
+	}
+	return p.zoomedSize()
+}
+
+func (p *imagePane) zoomedSize() fyne.Size {
+	zoom := p.view.zoom / p.view.scale()
+	return fyne.NewSize(p.view.pixels.Width*zoom, p.view.pixels.Height*zoom)
+}
+
+func (p *imagePane) Dragged(event *fyne.DragEvent) {
+	offset := p.view.scroll.Offset.Subtract(event.Dragged)
+	p.view.scroll.ScrollToOffset(fyne.NewPos(max(offset.X, 0), max(offset.Y, 0)))
+}
+
+func (p *imagePane) DragEnd() {
+}
+
+func (p *imagePane) DoubleTapped(_ *fyne.PointEvent) {
+	if p.view.OnDoubleTap != nil {
+		p.view.OnDoubleTap()
+	}
+}
+
+type imagePaneRenderer struct {
+	pane *imagePane
+}
+
+func (r *imagePaneRenderer) Layout(size fyne.Size) {
+	image := r.pane.image
+	if r.pane.view.zoom == 0 {
+		image.FillMode = canvas.ImageFillContain
+		image.Move(fyne.Position{})
+		image.Resize(size)
+		return
+	}
+	// centered, when smaller than the view
+	zoomed := r.pane.zoomedSize()
+	image.FillMode = canvas.ImageFillStretch
+	image.Move(fyne.NewPos(max((size.Width-zoomed.Width)/2, 0), max((size.Height-zoomed.Height)/2, 0)))
+	image.Resize(zoomed)
+}
+
+func (r *imagePaneRenderer) MinSize() fyne.Size {
+	return r.pane.MinSize()
+}
+
+func (r *imagePaneRenderer) Refresh() {
+	r.Layout(r.pane.Size())
+	canvas.Refresh(r.pane.image)
+}
+
+func (r *imagePaneRenderer) Objects() []fyne.CanvasObject {
+	return []fyne.CanvasObject{r.pane.image}
+}
+
+func (r *imagePaneRenderer) Destroy() {
+}
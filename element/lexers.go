@@ -0,0 +1,209 @@
+package element
+
+/*
+
+  File:    lexers.go
+  Author:  Bob Shofner
+
+  MIT License - https://opensource.org/license/mit/
+
+  This permission notice shall be included in all copies
+    or substantial portions of the Software.
+*/
+/*
+  Description: the built-in syntax lexers.
+  Others are added with RegisterLexer.
+*/
+
+import (
+	"bytes"
+)
+
+var GoLexer = &CodeLexer{
+	LineComments: []string{"//"},
+	BlockComment: [2]string{"/*", "*/"},
+	Quotes:       "\"'`",
+	RawQuote:     '`',
+	Keywords: Words("break case chan const continue default defer else fallthrough for func go goto " +
+		"if import interface map package range return select struct switch type var " +
+		"true false nil iota"),
+	Types: Words("bool byte complex64 complex128 error float32 float64 int int8 int16 int32 int64 " +
+		"rune string uint uint8 uint16 uint32 uint64 uintptr any comparable " +
+		"append cap clear close copy delete len make max min new panic print println recover"),
+}
+
+var JSONLexer = &CodeLexer{
+	Quotes:   "\"",
+	Keywords: Words("true false null"),
+	Keys:     true,
+}
+
+var YAMLLexer = &CodeLexer{
+	LineComments: []string{"#"},
+	WordComment:  true,
+	Quotes:       "\"'",
+	Keywords:     Words("true false yes no on off null ~"),
+	IdentChars:   "-./",
+	Keys:         true,
+}
+
+var ShellLexer = &CodeLexer{
+	LineComments: []string{"#"},
+	WordComment:  true,
+	Quotes:       "\"'",
+	Keywords: Words("if then else elif fi case esac for while until do done in function " +
+		"select return break continue local export readonly declare unset shift exit"),
+	Types:     Words("echo printf cd test read source eval exec set trap"),
+	Variables: true,
+}
+
+// MarkdownLexer colors headings, quotes, list markers, code, and links.
+// The state is inside a ``` fence.
+var MarkdownLexer = LexerFunc(func(line []byte, state int) ([]Token, int) {
+	trimmed := bytes.TrimLeft(line, " \t")
+	indent := len(line) - len(trimmed)
+	if bytes.HasPrefix(trimmed, []byte("```")) || bytes.HasPrefix(trimmed, []byte("~~~")) {
+		return []Token{{0, len(line), StringToken}}, 1 - state
+	}
+	if state == 1 {
+		return []Token{{0, len(line), StringToken}}, state
+	}
+	switch {
+	case bytes.HasPrefix(trimmed, []byte("#")):
+		return []Token{{0, len(line), HeadingToken}}, state
+	case bytes.HasPrefix(trimmed, []byte(">")):
+		return []Token{{0, len(line), CommentToken}}, state
+	}
+	var tokens []Token
+	start := 0
+	// list markers
+	if len(trimmed) > 1 && bytes.IndexByte([]byte("-*+"), trimmed[0]) >= 0 && trimmed[1] == ' ' {
+		start = indent + 1
+		tokens = append(tokens, Token{indent, start, KeywordToken})
+	} else if n := bytes.IndexByte(trimmed, '.'); n > 0 && n < 4 && n+1 < len(trimmed) &&
+		trimmed[n+1] == ' ' && len(bytes.TrimLeft(trimmed[:n], "0123456789")) == 0 {
+		start = indent + n + 1
+		tokens = append(tokens, Token{indent, start, KeywordToken})
+	}
+	for i := start; i < len(line); i++ {
+		switch line[i] {
+		case '`':
+			if end := bytes.IndexByte(line[i+1:], '`'); end >= 0 {
+				tokens = append(tokens, Token{i, i + end + 2, StringToken})
+				i += end + 1
+			}
+		case '[':
+			close := bytes.Index(line[i:], []byte("]("))
+			if close < 0 {
+				continue
+			}
+			end := bytes.IndexByte(line[i+close:], ')')
+			if end < 0 {
+				continue
+			}
+			tokens = append(tokens, Token{i, i + close + 1, KeyToken})
+			tokens = append(tokens, Token{i + close + 1, i + close + end + 1, CommentToken})
+			i += close + end
+		case '*', '_':
+			if line[i] == '_' && i > 0 && line[i-1] != ' ' && line[i-1] != '_' {
+				continue // snake_case
+			}
+			double := i+1 < len(line) && line[i+1] == line[i]
+			mark := line[i : i+1]
+			if double {
+				mark = line[i : i+2]
+			}
+			end := bytes.Index(line[i+len(mark):], mark)
+			if end > 0 {
+				tokens = append(tokens, Token{i, i + 2*len(mark) + end, TypeToken})
+				i += 2*len(mark) + end - 1
+			}
+		}
+	}
+	return tokens, state
+})
+
+// XMLLexer colors tags, attributes, values, entities and comments.
+// The states are inside a comment, and inside a tag.
+var XMLLexer = LexerFunc(func(line []byte, state int) ([]Token, int) {
+	const (
+		inText = iota
+		inXMLComment
+		inTag
+	)
+	var tokens []Token
+	isName := func(c byte) bool {
+		return c == '_' || c == ':' || c == '-' || c == '.' || isDigit(c) || (c|0x20 >= 'a' && c|0x20 <= 'z') || c >= 0x80
+	}
+	for i := 0; i < len(line); {
+		switch state {
+		case inXMLComment:
+			end := bytes.Index(line[i:], []byte("-->"))
+			if end < 0 {
+				return append(tokens, Token{i, len(line), CommentToken}), state
+			}
+			tokens = append(tokens, Token{i, i + end + 3, CommentToken})
+			i += end + 3
+			state = inText
+		case inTag:
+			c := line[i]
+			switch {
+			case c == '>' || (c == '/' || c == '?') && i+1 < len(line) && line[i+1] == '>':
+				end := i + 1
+				if c != '>' {
+					end++
+				}
+				tokens = append(tokens, Token{i, end, KeywordToken})
+				i = end
+				state = inText
+			case c == '"' || c == '\'':
+				end, _ := quoted(line, i, false)
+				tokens = append(tokens, Token{i, end, StringToken})
+				i = end
+			case isName(c):
+				start := i
+				for i < len(line) && isName(line[i]) {
+					i++
+				}
+				tokens = append(tokens, Token{start, i, KeyToken})
+			default:
+				i++
+			}
+		default:
+			switch {
+			case bytes.HasPrefix(line[i:], []byte("<!--")):
+				state = inXMLComment
+				tokens = append(tokens, Token{i, i + 4, CommentToken})
+				i += 4
+			case line[i] == '<':
+				start := i
+				i++
+				for i < len(line) && (line[i] == '/' || line[i] == '?' || line[i] == '!' || isName(line[i])) {
+					i++
+				}
+				tokens = append(tokens, Token{start, i, KeywordToken})
+				state = inTag
+			case line[i] == '&':
+				end := bytes.IndexByte(line[i:], ';')
+				if end > 0 && end < 12 {
+					tokens = append(tokens, Token{i, i + end + 1, NumberToken})
+					i += end + 1
+				} else {
+					i++
+				}
+			default:
+				i++
+			}
+		}
+	}
+	return tokens, state
+})
+
+func init() {
+	RegisterLexer(GoLexer, ".go")
+	RegisterLexer(JSONLexer, ".json", ".jsonc", ".geojson")
+	RegisterLexer(YAMLLexer, ".yaml", ".yml")
+	RegisterLexer(ShellLexer, ".sh", ".bash", ".zsh", ".ksh", ".bashrc", ".profile", ".zshrc")
+	RegisterLexer(MarkdownLexer, ".md", ".markdown")
+	RegisterLexer(XMLLexer, ".xml", ".xsd", ".xsl", ".svg", ".html", ".htm", ".plist", ".pom")
+}
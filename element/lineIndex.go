@@ -0,0 +1,187 @@
+package element
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"sync"
+)
+
+/*
+
+  File:    lineIndex.go
+  Author:  Bob Shofner
+
+  MIT License - https://opensource.org/license/mit/
+
+  This permission notice shall be included in all copies
+    or substantial portions of the Software.
+
+*/
+/*
+  Description: an index of the line starts in a (very large) file.
+  Only every indexStep'th line start is kept, other lines are found
+  by reading forward from the nearest one.
+*/
+
+const indexStep = 64
+
+// lines longer than this are truncated for display
+var maxLineBytes = 16 * 1024
+
+type lineIndex struct {
+	mu          sync.RWMutex
+	reader      io.ReaderAt
+	checkpoints []int64 // offset of line n*indexStep
+	lines       int     // complete lines
+	scanned     int64   // bytes indexed
+	partial     bool    // bytes after the last newline
+}
+
+func newLineIndex(reader io.ReaderAt) *lineIndex {
+	return &lineIndex{reader: reader, checkpoints: []int64{0}}
+}
+
+// extend indexes from the last scanned offset up to size.
+// progress is called after each block. A size less than scanned (truncated) restarts.
+func (ix *lineIndex) extend(size int64, cancel func() bool, progress func()) error {
+	ix.mu.Lock()
+	if size < ix.scanned {
+		ix.checkpoints = []int64{0}
+		ix.lines = 0
+		ix.scanned = 0
+		ix.partial = false
+	}
+	offset := ix.scanned
+	reader := ix.reader
+	ix.mu.Unlock()
+	buf := make([]byte, 1024*1024)
+	for offset < size {
+		if cancel != nil && cancel() {
+			return io.EOF
+		}
+		n := int64(len(buf))
+		if size-offset < n {
+			n = size - offset
+		}
+		nr, err := reader.ReadAt(buf[:n], offset)
+		if nr == 0 && err != nil {
+			return err
+		}
+		block := buf[:nr]
+		ix.mu.Lock()
+		base := offset
+		for {
+			i := bytes.IndexByte(block, '\n')
+			if i < 0 {
+				break
+			}
+			base += int64(i) + 1
+			block = block[i+1:]
+			ix.lines++
+			if ix.lines%indexStep == 0 {
+				ix.checkpoints = append(ix.checkpoints, base)
+			}
+		}
+		offset += int64(nr)
+		ix.scanned = offset
+		ix.partial = len(block) > 0
+		ix.mu.Unlock()
+		if progress != nil {
+			progress()
+		}
+	}
+	return nil
+}
+
+// reset starts over with a new reader (a rotated file)
+func (ix *lineIndex) reset(reader io.ReaderAt) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	ix.reader = reader
+	ix.checkpoints = []int64{0}
+	ix.lines = 0
+	ix.scanned = 0
+	ix.partial = false
+}
+
+// source is the reader being indexed
+func (ix *lineIndex) source() io.ReaderAt {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	return ix.reader
+}
+
+// count is the number of lines, including a last line without a newline
+func (ix *lineIndex) count() int {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	if ix.partial {
+		return ix.lines + 1
+	}
+	return ix.lines
+}
+
+// size of the indexed bytes
+func (ix *lineIndex) size() int64 {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	return ix.scanned
+}
+
+// start finds the checkpoint at or before line
+func (ix *lineIndex) start(line int) (reader io.ReaderAt, first int, offset int64, end int64) {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	c := line / indexStep
+	if c >= len(ix.checkpoints) {
+		c = len(ix.checkpoints) - 1
+	}
+	return ix.reader, c * indexStep, ix.checkpoints[c], ix.scanned
+}
+
+// read returns up to n lines starting at (0 based) line first.
+// The newline (and \r) are removed, long lines are truncated.
+func (ix *lineIndex) read(first, n int) [][]byte {
+	if n < 1 || first < 0 {
+		return nil
+	}
+	reader, line, offset, end := ix.start(first)
+	r := bufio.NewReaderSize(io.NewSectionReader(reader, offset, end-offset), 64*1024)
+	lines := make([][]byte, 0, n)
+	for len(lines) < n {
+		text, err := readLine(r)
+		if err != nil && len(text) == 0 {
+			break
+		}
+		if line >= first {
+			lines = append(lines, text)
+		}
+		line++
+		if err != nil {
+			break
+		}
+	}
+	return lines
+}
+
+// readLine reads a line (of at most maxLineBytes), skipping the rest.
+func readLine(r *bufio.Reader) ([]byte, error) {
+	var text []byte
+	for {
+		chunk, err := r.ReadSlice('\n')
+		if len(text) < maxLineBytes {
+			room := maxLineBytes - len(text)
+			if len(chunk) < room {
+				room = len(chunk)
+			}
+			text = append(text, chunk[:room]...)
+		}
+		if err == bufio.ErrBufferFull {
+			continue
+		}
+		text = bytes.TrimSuffix(text, []byte("\n"))
+		text = bytes.TrimSuffix(text, []byte("\r"))
+		return text, err
+	}
+}
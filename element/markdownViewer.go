@@ -0,0 +1,47 @@
+package element
+
+/*
+
+  File:    markdownViewer.go
+  Author:  Bob Shofner
+
+  MIT License - https://opensource.org/license/mit/
+
+  This permission notice shall be included in all copies
+    or substantial portions of the Software.
+*/
+/*
+  Description: Markdown, as formatted by RichText
+*/
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/widget"
+)
+
+type MarkdownViewer struct {
+	Content *fyne.Container
+	text    *widget.RichText
+}
+
+func NewMarkdownViewer(name string, markdown string, buttons []*widget.Button) *MarkdownViewer {
+	viewer := &MarkdownViewer{text: widget.NewRichTextFromMarkdown(markdown)}
+	viewer.text.Wrapping = fyne.TextWrapWord
+
+	title := widget.NewLabel(name)
+	title.Importance = widget.HighImportance
+	title.Alignment = fyne.TextAlignTrailing
+
+	buttonBar := container.NewHBox()
+	// add in any user buttons
+	if len(buttons) > 0 {
+		buttonBar.Objects = append(buttonBar.Objects, layout.NewSpacer())
+		for _, button := range buttons {
+			buttonBar.Objects = append(buttonBar.Objects, button)
+		}
+	}
+	viewer.Content = container.NewBorder(title, buttonBar, nil, nil, container.NewVScroll(viewer.text))
+	return viewer
+}
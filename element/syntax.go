@@ -0,0 +1,268 @@
+package element
+
+/*
+
+  File:    syntax.go
+  Author:  Bob Shofner
+
+  MIT License - https://opensource.org/license/mit/
+
+  This permission notice shall be included in all copies
+    or substantial portions of the Software.
+*/
+/*
+  Description: syntax highlighting for the TextViewer.
+  A SyntaxLexer splits a line into colored tokens, and is registered
+  for file extensions. Lexers see one line at a time, a state (as in a
+  block comment) is passed from line to line. The viewer only reads the
+  lines shown, so the state starts over at the top of the window.
+*/
+
+import (
+	"bytes"
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/theme"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+type TokenKind int
+
+const (
+	PlainToken TokenKind = iota
+	KeywordToken
+	TypeToken
+	StringToken
+	NumberToken
+	CommentToken
+	KeyToken // map keys, tags, attribute names
+	HeadingToken
+)
+
+var TokenColors = map[TokenKind]fyne.ThemeColorName{
+	KeywordToken: theme.ColorNamePrimary,
+	TypeToken:    theme.ColorNameHyperlink,
+	StringToken:  theme.ColorNameSuccess,
+	NumberToken:  theme.ColorNameWarning,
+	CommentToken: theme.ColorNameDisabled,
+	KeyToken:     theme.ColorNameHyperlink,
+	HeadingToken: theme.ColorNamePrimary,
+}
+
+// Token is a span of bytes in a line
+type Token struct {
+	Start int
+	End   int
+	Kind  TokenKind
+}
+
+type SyntaxLexer interface {
+	// Tokens of a line. state is from the line before, 0 at the start.
+	Tokens(line []byte, state int) (tokens []Token, next int)
+}
+
+// LexerFunc is a function used as a SyntaxLexer
+type LexerFunc func(line []byte, state int) ([]Token, int)
+
+func (f LexerFunc) Tokens(line []byte, state int) ([]Token, int) {
+	return f(line, state)
+}
+
+var lexersLock sync.RWMutex
+var lexers = make(map[string]SyntaxLexer)
+
+// RegisterLexer uses a lexer for files with the extensions (as ".go"), or whole names (as "Makefile")
+func RegisterLexer(lexer SyntaxLexer, extensions ...string) {
+	lexersLock.Lock()
+	defer lexersLock.Unlock()
+	for _, ext := range extensions {
+		lexers[strings.ToLower(ext)] = lexer
+	}
+}
+
+// LexerFor finds the lexer for a file name, nil if none
+func LexerFor(name string) SyntaxLexer {
+	lexersLock.RLock()
+	defer lexersLock.RUnlock()
+	base := strings.ToLower(filepath.Base(name))
+	if lexer, ok := lexers[base]; ok {
+		return lexer
+	}
+	return lexers[strings.ToLower(filepath.Ext(base))]
+}
+
+// lexer states of a CodeLexer
+const (
+	inCode = iota
+	inComment
+	inString
+)
+
+// CodeLexer colors the common parts of programming and configuration languages
+type CodeLexer struct {
+	LineComments []string // as "//"
+	WordComment  bool     // a line comment must start a word (as # in shell)
+	BlockComment [2]string
+	Quotes       string // string delimiters
+	RawQuote     byte   // a quote without escapes, that may span lines (as ` in Go)
+	Keywords     map[string]bool
+	Types        map[string]bool
+	IdentChars   string // allowed in names, besides letters, digits and _
+	Keys         bool   // a string or name followed by : is a key
+	Variables    bool   // $NAME and ${NAME}
+}
+
+// Words makes a set of space separated words
+func Words(words string) map[string]bool {
+	set := make(map[string]bool)
+	for _, word := range strings.Fields(words) {
+		set[word] = true
+	}
+	return set
+}
+
+func (l *CodeLexer) Tokens(line []byte, state int) ([]Token, int) {
+	var tokens []Token
+	i := 0
+	switch state {
+	case inComment:
+		end := bytes.Index(line, []byte(l.BlockComment[1]))
+		if end < 0 {
+			return []Token{{0, len(line), CommentToken}}, inComment
+		}
+		i = end + len(l.BlockComment[1])
+		tokens = append(tokens, Token{0, i, CommentToken})
+	case inString:
+		end := bytes.IndexByte(line, l.RawQuote)
+		if end < 0 {
+			return []Token{{0, len(line), StringToken}}, inString
+		}
+		i = end + 1
+		tokens = append(tokens, Token{0, i, StringToken})
+	}
+	for i < len(line) {
+		c := line[i]
+		if l.lineComment(line, i) {
+			return append(tokens, Token{i, len(line), CommentToken}), inCode
+		}
+		if l.BlockComment[0] != "" && bytes.HasPrefix(line[i:], []byte(l.BlockComment[0])) {
+			start := i
+			end := bytes.Index(line[i+len(l.BlockComment[0]):], []byte(l.BlockComment[1]))
+			if end < 0 {
+				return append(tokens, Token{start, len(line), CommentToken}), inComment
+			}
+			i += len(l.BlockComment[0]) + end + len(l.BlockComment[1])
+			tokens = append(tokens, Token{start, i, CommentToken})
+			continue
+		}
+		if strings.IndexByte(l.Quotes, c) >= 0 {
+			start := i
+			end, closed := quoted(line, i, c != l.RawQuote)
+			i = end
+			if !closed && c == l.RawQuote {
+				return append(tokens, Token{start, len(line), StringToken}), inString
+			}
+			kind := StringToken
+			if l.Keys && isKey(line, i) {
+				kind = KeyToken
+			}
+			tokens = append(tokens, Token{start, i, kind})
+			continue
+		}
+		if l.Variables && c == '$' && i+1 < len(line) {
+			start := i
+			if line[i+1] == '{' {
+				end := bytes.IndexByte(line[i:], '}')
+				if end < 0 {
+					end = len(line) - i - 1
+				}
+				i += end + 1
+			} else {
+				i = l.name(line, i+1)
+				if i == start+1 {
+					i++ // $?, $1 ...
+				}
+			}
+			tokens = append(tokens, Token{start, i, TypeToken})
+			continue
+		}
+		if isDigit(c) && (i == 0 || !l.isIdent(line[i-1])) {
+			start := i
+			for i < len(line) && (l.isIdent(line[i]) || line[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, Token{start, i, NumberToken})
+			continue
+		}
+		if l.isIdent(c) {
+			start := i
+			i = l.name(line, i)
+			word := string(line[start:i])
+			switch {
+			case l.Keys && isKey(line, i):
+				tokens = append(tokens, Token{start, i, KeyToken})
+			case l.Keywords[word]:
+				tokens = append(tokens, Token{start, i, KeywordToken})
+			case l.Types[word]:
+				tokens = append(tokens, Token{start, i, TypeToken})
+			}
+			continue
+		}
+		i++
+	}
+	return tokens, inCode
+}
+
+func (l *CodeLexer) lineComment(line []byte, i int) bool {
+	if l.WordComment && i > 0 && line[i-1] != ' ' && line[i-1] != '\t' {
+		return false
+	}
+	for _, comment := range l.LineComments {
+		if bytes.HasPrefix(line[i:], []byte(comment)) {
+			return true
+		}
+	}
+	return false
+}
+
+func (l *CodeLexer) isIdent(c byte) bool {
+	return c == '_' || isDigit(c) || (c|0x20 >= 'a' && c|0x20 <= 'z') || c >= 0x80 ||
+		strings.IndexByte(l.IdentChars, c) >= 0
+}
+
+// name is the end of the name at i
+func (l *CodeLexer) name(line []byte, i int) int {
+	for i < len(line) && l.isIdent(line[i]) {
+		i++
+	}
+	return i
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// quoted finds the end of a quoted string at i, and if it was closed on the line
+func quoted(line []byte, i int, escapes bool) (int, bool) {
+	quote := line[i]
+	for i++; i < len(line); i++ {
+		if escapes && line[i] == '\\' {
+			i++
+			continue
+		}
+		if line[i] == quote {
+			return i + 1, true
+		}
+	}
+	return len(line), false
+}
+
+// isKey checks for a : after i (and white space)
+func isKey(line []byte, i int) bool {
+	for i < len(line) && (line[i] == ' ' || line[i] == '\t') {
+		i++
+	}
+	return i < len(line) && line[i] == ':' && (i+1 == len(line) || line[i+1] == ' ' ||
+		line[i+1] == '\t' || line[i+1] == '"' || line[i+1] == '{' || line[i+1] == '[' || isDigit(line[i+1]))
+}
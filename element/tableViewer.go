@@ -0,0 +1,198 @@
+package element
+
+/*
+
+  File:    tableViewer.go
+  Author:  Bob Shofner
+
+  MIT License - https://opensource.org/license/mit/
+
+  This permission notice shall be included in all copies
+    or substantial portions of the Software.
+*/
+/*
+  Description: a table of CSV / TSV records, sorted by tapping a column heading.
+  Columns of numbers sort by value, others alphabetically.
+*/
+
+import (
+	"fmt"
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type TableViewer struct {
+	Content    *fyne.Container
+	table      *widget.Table
+	records    [][]string
+	columns    int
+	header     bool  // the first record names the columns
+	order      []int // of the records shown
+	sortColumn int   // -1 for the file order
+	descending bool
+	status     *widget.Label
+}
+
+func NewTableViewer(name string, records [][]string, buttons []*widget.Button) *TableViewer {
+	viewer := &TableViewer{
+		records:    records,
+		header:     true,
+		sortColumn: -1,
+		status:     widget.NewLabel(""),
+	}
+	for _, record := range records {
+		viewer.columns = max(viewer.columns, len(record))
+	}
+
+	title := widget.NewLabel(name)
+	title.Importance = widget.HighImportance
+	title.Alignment = fyne.TextAlignTrailing
+
+	viewer.table = widget.NewTableWithHeaders(func() (int, int) {
+		return len(viewer.order), viewer.columns
+	}, func() fyne.CanvasObject {
+		label := widget.NewLabel("")
+		label.Truncation = fyne.TextTruncateEllipsis
+		return label
+	}, func(id widget.TableCellID, object fyne.CanvasObject) {
+		object.(*widget.Label).SetText(viewer.cell(id.Row, id.Col))
+	})
+	viewer.table.ShowHeaderColumn = false
+	viewer.table.CreateHeader = func() fyne.CanvasObject {
+		button := widget.NewButton("", nil)
+		button.Alignment = widget.ButtonAlignLeading
+		return button
+	}
+	viewer.table.UpdateHeader = func(id widget.TableCellID, object fyne.CanvasObject) {
+		button := object.(*widget.Button)
+		col := id.Col
+		button.SetText(viewer.heading(col))
+		button.SetIcon(nil)
+		if col == viewer.sortColumn {
+			if viewer.descending {
+				button.SetIcon(theme.MoveDownIcon())
+			} else {
+				button.SetIcon(theme.MoveUpIcon())
+			}
+		}
+		button.OnTapped = func() {
+			viewer.sortBy(col)
+		}
+	}
+
+	header := widget.NewCheck("header row", func(on bool) {
+		viewer.header = on
+		viewer.sort()
+	})
+	header.SetChecked(viewer.header)
+	unsorted := widget.NewButton("FILE ORDER", func() {
+		viewer.sortColumn = -1
+		viewer.descending = false
+		viewer.sort()
+	})
+	buttonBar := container.NewHBox(header, unsorted, viewer.status)
+
+	// add in any user buttons
+	if len(buttons) > 0 {
+		buttonBar.Objects = append(buttonBar.Objects, layout.NewSpacer())
+		for _, button := range buttons {
+			buttonBar.Objects = append(buttonBar.Objects, button)
+		}
+	}
+	viewer.Content = container.NewBorder(title, buttonBar, nil, nil, viewer.table)
+	viewer.sort()
+	viewer.sizeColumns()
+	return viewer
+}
+
+// heading of a column, its number without a header row
+func (v *TableViewer) heading(col int) string {
+	if v.header && len(v.records) > 0 && col < len(v.records[0]) {
+		return v.records[0][col]
+	}
+	return strconv.Itoa(col + 1)
+}
+
+func (v *TableViewer) cell(row, col int) string {
+	if row >= len(v.order) {
+		return ""
+	}
+	record := v.records[v.order[row]]
+	if col >= len(record) {
+		return ""
+	}
+	return record[col]
+}
+
+// sizeColumns fits the widths to the headings and the first rows
+func (v *TableViewer) sizeColumns() {
+	style := fyne.TextStyle{}
+	padding := 4 * theme.Padding()
+	for col := 0; col < v.columns; col++ {
+		width := fyne.MeasureText(v.heading(col), theme.TextSize(), style).Width + theme.IconInlineSize()
+		for row := 0; row < len(v.order) && row < 200; row++ {
+			width = max(width, fyne.MeasureText(v.cell(row, col), theme.TextSize(), style).Width)
+		}
+		v.table.SetColumnWidth(col, min(max(width+padding, 40), 400))
+	}
+}
+
+// sortBy sorts on a column, reversing the order if already sorted by it
+func (v *TableViewer) sortBy(col int) {
+	v.descending = col == v.sortColumn && !v.descending
+	v.sortColumn = col
+	v.sort()
+}
+
+// sort orders the records, sortColumn -1 is the order of the file
+func (v *TableViewer) sort() {
+	col := v.sortColumn
+	first := 0
+	if v.header && len(v.records) > 0 {
+		first = 1
+	}
+	v.order = make([]int, 0, len(v.records))
+	for i := first; i < len(v.records); i++ {
+		v.order = append(v.order, i)
+	}
+	if col >= 0 {
+		value := func(i int) string {
+			if col < len(v.records[i]) {
+				return strings.TrimSpace(v.records[i][col])
+			}
+			return ""
+		}
+		numeric := true
+		for _, i := range v.order {
+			if s := value(i); s != "" {
+				if _, err := strconv.ParseFloat(s, 64); err != nil {
+					numeric = false
+					break
+				}
+			}
+		}
+		sort.SliceStable(v.order, func(a, b int) bool {
+			x, y := value(v.order[a]), value(v.order[b])
+			if v.descending {
+				x, y = y, x
+			}
+			if numeric {
+				fx, errX := strconv.ParseFloat(x, 64)
+				fy, errY := strconv.ParseFloat(y, 64)
+				if errX != nil || errY != nil {
+					return errX != nil && errY == nil // blanks first
+				}
+				return fx < fy
+			}
+			return strings.ToLower(x) < strings.ToLower(y)
+		})
+	}
+	v.status.SetText(fmt.Sprintf("%d rows, %d columns", len(v.order), v.columns))
+	v.table.Refresh()
+}
@@ -0,0 +1,83 @@
+package tappable
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"time"
+)
+
+/*
+
+  File:    thumbnail.go
+  Author:  Bob Shofner
+
+  MIT License - https://opensource.org/license/mit/
+
+  This permission notice shall be included in all copies
+    or substantial portions of the Software.
+
+*/
+/*
+  Description: A tappable image over its name, highlighted when selected.
+  The Image and Label may be set / reset.
+*/
+
+type Thumbnail struct {
+	widget.BaseWidget
+	ID         int
+	OnTapped   OnTap
+	Image      *canvas.Image
+	Label      *widget.Label
+	background *canvas.Rectangle
+	lastTime   time.Time
+}
+
+func NewThumbnail(size float32, onTap OnTap) *Thumbnail {
+	t := &Thumbnail{
+		OnTapped:   onTap,
+		Image:      canvas.NewImageFromImage(nil),
+		Label:      widget.NewLabel(""),
+		background: canvas.NewRectangle(theme.Color(theme.ColorNameSelection)),
+	}
+	t.Image.FillMode = canvas.ImageFillContain
+	t.Image.SetMinSize(fyne.NewSize(size, size))
+	t.Label.Alignment = fyne.TextAlignCenter
+	t.Label.Truncation = fyne.TextTruncateEllipsis
+	t.background.CornerRadius = theme.InputRadiusSize()
+	t.background.Hide()
+	t.ExtendBaseWidget(t)
+	return t
+}
+
+func (t *Thumbnail) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(container.NewStack(t.background,
+		container.NewBorder(nil, t.Label, nil, nil, t.Image)))
+}
+
+// SetSelected highlights the thumbnail
+func (t *Thumbnail) SetSelected(selected bool) {
+	if selected {
+		t.background.FillColor = theme.Color(theme.ColorNameSelection)
+		t.background.Show()
+	} else {
+		t.background.Hide()
+	}
+	t.background.Refresh()
+}
+
+func (t *Thumbnail) Tapped(pe *fyne.PointEvent) {
+	duration := time.Now().Sub(t.lastTime)
+	if duration <= TapperDoubleClickTime {
+		t.OnTapped(Double, t.ID, pe)
+		t.lastTime = time.Time{}
+	} else {
+		t.OnTapped(Primary, t.ID, pe)
+		t.lastTime = time.Now()
+	}
+}
+func (t *Thumbnail) TappedSecondary(pe *fyne.PointEvent) {
+	t.OnTapped(Secondary, t.ID, pe)
+}
@@ -0,0 +1,424 @@
+package element
+
+/*
+
+  File:    textDiff.go
+  Author:  Bob Shofner
+
+  MIT License - https://opensource.org/license/mit/
+
+  This permission notice shall be included in all copies
+    or substantial portions of the Software.
+*/
+/*
+  Description: side by side differences of two texts.
+  The lines are aligned, with blank rows across from lines only on one side.
+  A change may be copied to the other side, and the changed side saved by the owner.
+*/
+
+import (
+	"fman/fileutil"
+	"fmt"
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"image/color"
+	"strconv"
+	"strings"
+)
+
+// diffRow is a row of the view, with the line of each side (-1 for none)
+type diffRow struct {
+	line [2]int
+	hunk int
+}
+
+type TextDiff struct {
+	Content     *fyne.Container
+	OnSave      func(right bool, text string) error // required to save
+	window      fyne.Window
+	names       [2]string
+	lines       [2][]string
+	newline     [2]bool // the text ends with a new line
+	edited      [2]bool
+	ignoreSpace bool
+	hunks       []fileutil.DiffHunk
+	changes     []int // the hunks that differ
+	starts      []int // first row of each hunk
+	rows        []diffRow
+	current     int // of changes, -1 for none
+	pages       [2]*textPage
+	titles      [2]*widget.Label
+	saves       [2]*widget.Button
+	copies      [2]*widget.Button
+	bar         *widget.Slider
+	status      *widget.Label
+	tabSize     int
+	top         int
+	left        int
+	settingBar  bool
+}
+
+func NewTextDiff(window fyne.Window, leftName, left, rightName, right string, buttons []*widget.Button,
+	tabSize int) *TextDiff {
+
+	diff := &TextDiff{
+		window:  window,
+		names:   [2]string{leftName, rightName},
+		status:  widget.NewLabel(""),
+		tabSize: tabSize,
+		current: -1,
+	}
+	if diff.tabSize < 1 {
+		diff.tabSize = 4
+	}
+	diff.lines[0], diff.newline[0] = splitLines(left)
+	diff.lines[1], diff.newline[1] = splitLines(right)
+
+	for side := range diff.pages {
+		diff.titles[side] = widget.NewLabel(diff.names[side])
+		diff.titles[side].Importance = widget.HighImportance
+		page := newTextPage()
+		page.grid.TabWidth = diff.tabSize
+		page.OnResize = func(_, _ int) {
+			diff.setTop(diff.top)
+		}
+		page.OnScroll = func(rows, cols int) {
+			diff.setLeft(diff.left + cols)
+			diff.setTop(diff.top + rows)
+		}
+		page.OnKey = diff.typedKey
+		diff.pages[side] = page
+	}
+
+	ignoreSpace := widget.NewCheck("ignore white space", func(on bool) {
+		diff.ignoreSpace = on
+		diff.diff()
+		diff.showChange(0)
+	})
+	previous := widget.NewButtonWithIcon("", theme.MoveUpIcon(), func() {
+		diff.nextChange(-1)
+	})
+	next := widget.NewButtonWithIcon("", theme.MoveDownIcon(), func() {
+		diff.nextChange(1)
+	})
+	diff.copies[1] = widget.NewButtonWithIcon("COPY", theme.NavigateNextIcon(), func() {
+		diff.copyChange(1)
+	})
+	diff.copies[1].IconPlacement = widget.ButtonIconTrailingText
+	diff.copies[0] = widget.NewButtonWithIcon("COPY", theme.NavigateBackIcon(), func() {
+		diff.copyChange(0)
+	})
+	diff.saves[0] = widget.NewButtonWithIcon("SAVE LEFT", theme.DocumentSaveIcon(), func() {
+		diff.save(0)
+	})
+	diff.saves[1] = widget.NewButtonWithIcon("SAVE RIGHT", theme.DocumentSaveIcon(), func() {
+		diff.save(1)
+	})
+	buttonBar := container.NewHBox(ignoreSpace, previous, next, diff.copies[0], diff.copies[1],
+		diff.saves[0], diff.saves[1], diff.status)
+
+	// add in any user buttons
+	if len(buttons) > 0 {
+		buttonBar.Objects = append(buttonBar.Objects, layout.NewSpacer())
+		for _, button := range buttons {
+			buttonBar.Objects = append(buttonBar.Objects, button)
+		}
+	}
+
+	diff.bar = widget.NewSlider(0, 1)
+	diff.bar.Orientation = widget.Vertical
+	diff.bar.Step = 1
+	diff.bar.OnChanged = func(value float64) {
+		if !diff.settingBar {
+			diff.setTop(int(diff.bar.Max - value))
+		}
+	}
+
+	titles := container.NewGridWithColumns(2, diff.titles[0], diff.titles[1])
+	pages := container.NewGridWithColumns(2, diff.pages[0], diff.pages[1])
+	diff.Content = container.NewBorder(titles, buttonBar, nil, diff.bar, pages)
+	diff.diff()
+	diff.showChange(0)
+	return diff
+}
+
+// Edited reports unsaved copies
+func (d *TextDiff) Edited() bool {
+	return d.edited[0] || d.edited[1]
+}
+
+// splitLines of a text, and if it ends with a new line (as an empty text will)
+func splitLines(text string) ([]string, bool) {
+	if text == "" {
+		return nil, true
+	}
+	newline := strings.HasSuffix(text, "\n")
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n"), newline
+}
+
+// diff compares the lines, and aligns the rows
+func (d *TextDiff) diff() {
+	d.hunks = fileutil.DiffLines(d.lines[0], d.lines[1], d.ignoreSpace)
+	d.changes = make([]int, 0)
+	d.starts = make([]int, len(d.hunks))
+	d.rows = make([]diffRow, 0, max(len(d.lines[0]), len(d.lines[1])))
+	for h, hunk := range d.hunks {
+		if !hunk.Equal {
+			d.changes = append(d.changes, h)
+		}
+		d.starts[h] = len(d.rows)
+		for i := 0; i < max(hunk.A2-hunk.A1, hunk.B2-hunk.B1); i++ {
+			row := diffRow{line: [2]int{-1, -1}, hunk: h}
+			if hunk.A1+i < hunk.A2 {
+				row.line[0] = hunk.A1 + i
+			}
+			if hunk.B1+i < hunk.B2 {
+				row.line[1] = hunk.B1 + i
+			}
+			d.rows = append(d.rows, row)
+		}
+	}
+	d.current = -1
+}
+
+// nextChange steps forward (1) or back (-1) through the changes
+func (d *TextDiff) nextChange(step int) {
+	if d.current < 0 {
+		// from the first change after the top of the page
+		n := 0
+		for n < len(d.changes) && d.starts[d.changes[n]] < d.top {
+			n++
+		}
+		if step < 0 {
+			n--
+		}
+		d.showChange(n)
+		return
+	}
+	d.showChange(d.current + step)
+}
+
+// showChange scrolls a change into view, and makes it current
+func (d *TextDiff) showChange(n int) {
+	if n >= 0 && n < len(d.changes) {
+		d.current = n
+		rows := max(d.pages[0].rows, 1)
+		start := d.starts[d.changes[n]]
+		if start < d.top || start >= d.top+rows {
+			d.top = start - rows/3
+		}
+	}
+	d.setTop(d.top)
+	d.showStatus()
+}
+
+// copyChange copies the current change to a side (0 left, 1 right)
+func (d *TextDiff) copyChange(to int) {
+	if d.current < 0 {
+		return
+	}
+	hunk := d.hunks[d.changes[d.current]]
+	from := 1 - to
+	ends := [2][2]int{{hunk.A1, hunk.A2}, {hunk.B1, hunk.B2}}
+	lines := append([]string{}, d.lines[to][:ends[to][0]]...)
+	lines = append(lines, d.lines[from][ends[from][0]:ends[from][1]]...)
+	d.lines[to] = append(lines, d.lines[to][ends[to][1]:]...)
+	d.edited[to] = true
+	d.diff()
+
+	// go on to the next change, the lines of the from side are the same
+	n := 0
+	for n < len(d.changes) {
+		h := d.hunks[d.changes[n]]
+		if (from == 0 && h.A1 >= ends[0][0]) || (from == 1 && h.B1 >= ends[1][0]) {
+			break
+		}
+		n++
+	}
+	if n == len(d.changes) {
+		n--
+	}
+	d.showChange(n)
+}
+
+func (d *TextDiff) save(side int) {
+	if d.OnSave == nil {
+		return
+	}
+	text := strings.Join(d.lines[side], "\n")
+	if d.newline[side] && len(d.lines[side]) > 0 {
+		text += "\n"
+	}
+	if err := d.OnSave(side == 1, text); err != nil {
+		dialog.ShowError(err, d.window)
+		return
+	}
+	d.edited[side] = false
+	d.showStatus()
+}
+
+func (d *TextDiff) showStatus() {
+	for side := range d.titles {
+		title := d.names[side]
+		if d.edited[side] {
+			title += " *"
+			d.saves[side].Enable()
+		} else {
+			d.saves[side].Disable()
+		}
+		d.titles[side].SetText(title)
+		if d.current >= 0 {
+			d.copies[side].Enable()
+		} else {
+			d.copies[side].Disable()
+		}
+	}
+	switch {
+	case len(d.changes) == 0:
+		d.status.SetText("no differences")
+	case d.current >= 0:
+		d.status.SetText(fmt.Sprintf("change %d of %d", d.current+1, len(d.changes)))
+	default:
+		d.status.SetText(fmt.Sprintf("%d changes", len(d.changes)))
+	}
+}
+
+// lastTop is the top row that shows the end
+func (d *TextDiff) lastTop() int {
+	return max(len(d.rows)-max(d.pages[0].rows, 1), 0)
+}
+
+// setTop scrolls both sides to show a (0 based) row at the top
+func (d *TextDiff) setTop(top int) {
+	last := d.lastTop()
+	d.top = max(min(top, last), 0)
+	d.settingBar = true
+	d.bar.Max = max(float64(last), 1) // a slider needs a range
+	d.bar.SetValue(d.bar.Max - float64(d.top))
+	d.settingBar = false
+	d.render()
+}
+
+func (d *TextDiff) setLeft(left int) {
+	d.left = max(left, 0)
+}
+
+func (d *TextDiff) typedKey(key *fyne.KeyEvent) {
+	page := max(d.pages[0].rows-1, 1)
+	switch key.Name {
+	case fyne.KeyUp:
+		d.setTop(d.top - 1)
+	case fyne.KeyDown:
+		d.setTop(d.top + 1)
+	case fyne.KeyPageUp:
+		d.setTop(d.top - page)
+	case fyne.KeyPageDown:
+		d.setTop(d.top + page)
+	case fyne.KeyHome:
+		d.setLeft(0)
+		d.setTop(0)
+	case fyne.KeyEnd:
+		d.setTop(d.lastTop())
+	case fyne.KeyLeft:
+		d.setLeft(d.left - d.tabSize)
+		d.render()
+	case fyne.KeyRight:
+		d.setLeft(d.left + d.tabSize)
+		d.render()
+	case fyne.KeyF3, fyne.KeyReturn, fyne.KeyEnter:
+		d.nextChange(1)
+	case fyne.KeyF2:
+		d.nextChange(-1)
+	}
+}
+
+// render fills both grids. Changed lines are tinted, red for only on the left,
+// green for only on the right, and more strongly for the current change.
+func (d *TextDiff) render() {
+	if d.pages[0].rows < 1 || d.pages[1].rows < 1 {
+		return // not laid out yet
+	}
+	current := -1
+	if d.current >= 0 {
+		current = d.changes[d.current]
+	}
+	background := theme.Color(theme.ColorNameBackground)
+	tint := func(name fyne.ThemeColorName, strong bool) widget.TextGridStyle {
+		amount := 0.25
+		if strong {
+			amount = 0.5
+		}
+		return &widget.CustomTextGridStyle{
+			TextStyle: fyne.TextStyle{Monospace: true},
+			BGColor:   blend(background, theme.Color(name), amount),
+		}
+	}
+	for side, page := range d.pages {
+		gutter := len(strconv.Itoa(len(d.lines[side])))
+		rows := make([]widget.TextGridRow, 0, page.rows)
+		for r := d.top; r < len(d.rows) && r < d.top+page.rows; r++ {
+			row := d.rows[r]
+			line := row.line[side]
+			var cells []widget.TextGridCell
+			if line >= 0 {
+				cells, _ = expandTabs([]byte(d.lines[side][line]), d.tabSize)
+			}
+			if d.left < len(cells) {
+				cells = cells[d.left:]
+			} else {
+				cells = nil
+			}
+			if !d.hunks[row.hunk].Equal {
+				var style widget.TextGridStyle
+				switch {
+				case row.line[0] >= 0 && row.line[1] >= 0:
+					style = tint(theme.ColorNameWarning, row.hunk == current)
+				case row.line[1-side] >= 0 || line < 0:
+					style = tint(theme.ColorNameDisabled, row.hunk == current)
+				case side == 0:
+					style = tint(theme.ColorNameError, row.hunk == current)
+				default:
+					style = tint(theme.ColorNameSuccess, row.hunk == current)
+				}
+				// the whole width of the page
+				for len(cells) < page.cols-gutter-1 {
+					cells = append(cells, widget.TextGridCell{Rune: ' '})
+				}
+				for c := range cells {
+					cells[c].Style = style
+				}
+			}
+			if line >= 0 {
+				cells = append(lineNumber(line+1, gutter), cells...)
+			} else {
+				cells = append(blankNumber(gutter), cells...)
+			}
+			rows = append(rows, widget.TextGridRow{Cells: cells})
+		}
+		page.grid.Rows = rows
+		page.grid.Refresh()
+	}
+}
+
+// blankNumber is a gutter without a line number
+func blankNumber(width int) []widget.TextGridCell {
+	cells := make([]widget.TextGridCell, 0, width+1)
+	for i := 0; i < width; i++ {
+		cells = append(cells, widget.TextGridCell{Rune: ' ', Style: widget.TextGridStyleWhitespace})
+	}
+	return append(cells, widget.TextGridCell{Rune: '|', Style: widget.TextGridStyleWhitespace})
+}
+
+// blend mixes an amount (0 to 1) of color c into the background
+func blend(background, c color.Color, amount float64) color.Color {
+	r1, g1, b1, _ := background.RGBA()
+	r2, g2, b2, _ := c.RGBA()
+	mix := func(x, y uint32) uint8 {
+		return uint8((float64(x)*(1-amount) + float64(y)*amount) / 257)
+	}
+	return color.NRGBA{R: mix(r1, r2), G: mix(g1, g2), B: mix(b1, b2), A: 0xff}
+}
@@ -0,0 +1,531 @@
+package element
+
+/*
+
+  File:    textEditor.go
+  Author:  Bob Shofner
+
+  MIT License - https://opensource.org/license/mit/
+
+  This permission notice shall be included in all copies
+    or substantial portions of the Software.
+*/
+/*
+  Description: a text editor, for files small enough to edit in memory.
+  The text is edited as UTF-8 with \n line endings, and saved by the owner
+  in its fileutil.TextFormat. Undo keeps snapshots of the whole text, as
+  replacing text clears the Entry's own undo.
+*/
+
+import (
+	"errors"
+	"fman/fileutil"
+	"fmt"
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/driver/desktop"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	undoPause    = time.Second // typing without a pause is undone together
+	maxUndo      = 200
+	maxUndoBytes = 64 * 1024 * 1024
+)
+
+// snapshot of the text, for undo / redo
+type snapshot struct {
+	text     string
+	row, col int
+}
+
+type TextEditor struct {
+	Content    *fyne.Container
+	OnSave     func(text string, format fileutil.TextFormat) error // required to save
+	OnSaveAs   func()                                              // get a new name, then Save
+	name       string
+	window     fyne.Window
+	entry      *editorEntry
+	title      *widget.Label
+	status     *widget.Label
+	message    string // shown after the cursor position, until the next change
+	save       *widget.Button
+	undoButton *widget.Button
+	redoButton *widget.Button
+	encodings  *widget.Select
+	endings    *widget.Select
+	bom        *widget.Check
+	format     fileutil.TextFormat
+	saved      string
+	savedAs    fileutil.TextFormat
+	text       string // before the change being typed
+	undo       []snapshot
+	redo       []snapshot
+	lastChange time.Time
+	restoring  bool
+	setting    bool
+
+	findBar       *fyne.Container
+	find          *widget.Entry
+	replace       *widget.Entry
+	caseSensitive *widget.Check
+	isRegEx       *widget.Check
+}
+
+func NewTextEditor(window fyne.Window, name string, text string, format fileutil.TextFormat,
+	buttons []*widget.Button) *TextEditor {
+
+	editor := &TextEditor{
+		name:    name,
+		window:  window,
+		title:   widget.NewLabel(name),
+		status:  widget.NewLabel(""),
+		format:  format,
+		saved:   text,
+		savedAs: format,
+		text:    text,
+	}
+	editor.title.Importance = widget.HighImportance
+	editor.title.Alignment = fyne.TextAlignTrailing
+
+	editor.entry = newEditorEntry(editor)
+	editor.entry.SetText(text)
+	editor.entry.OnChanged = editor.changed
+	editor.entry.OnCursorChanged = editor.showStatus
+
+	editor.save = widget.NewButtonWithIcon("SAVE", theme.DocumentSaveIcon(), func() {
+		editor.Save()
+	})
+	saveAs := widget.NewButton("SAVE AS...", func() {
+		if editor.OnSaveAs != nil {
+			editor.OnSaveAs()
+		}
+	})
+	editor.undoButton = widget.NewButtonWithIcon("", theme.ContentUndoIcon(), func() {
+		editor.Undo()
+	})
+	editor.redoButton = widget.NewButtonWithIcon("", theme.ContentRedoIcon(), func() {
+		editor.Redo()
+	})
+	find := widget.NewButtonWithIcon("FIND...", theme.SearchReplaceIcon(), func() {
+		editor.showFind()
+	})
+
+	editor.encodings = widget.NewSelect(fileutil.Encodings, func(name string) {
+		if !editor.setting {
+			editor.format.Encoding = name
+			editor.showStatus()
+		}
+	})
+	editor.endings = widget.NewSelect(fileutil.LineEndings, func(ending string) {
+		if !editor.setting {
+			editor.format.LineEnding = ending
+			editor.format.Endings = "" // all lines
+			editor.showStatus()
+		}
+	})
+	editor.bom = widget.NewCheck("BOM", func(on bool) {
+		if !editor.setting {
+			editor.format.BOM = on
+			editor.showStatus()
+		}
+	})
+	editor.setting = true
+	editor.encodings.SetSelected(format.Encoding)
+	editor.endings.SetSelected(format.LineEnding)
+	editor.bom.SetChecked(format.BOM)
+	editor.setting = false
+
+	buttonBar := container.NewHBox(editor.save, saveAs, editor.undoButton, editor.redoButton, find,
+		editor.encodings, editor.endings, editor.bom, editor.status)
+
+	// add in any user buttons
+	if len(buttons) > 0 {
+		buttonBar.Objects = append(buttonBar.Objects, layout.NewSpacer())
+		for _, button := range buttons {
+			buttonBar.Objects = append(buttonBar.Objects, button)
+		}
+	}
+
+	editor.findBar = editor.newFindBar()
+	editor.findBar.Hide()
+
+	editor.Content = container.NewBorder(editor.title, container.NewVBox(editor.findBar, buttonBar),
+		nil, nil, editor.entry)
+	editor.showStatus()
+	return editor
+}
+
+// Dirty reports unsaved changes
+func (ed *TextEditor) Dirty() bool {
+	return ed.entry.Text != ed.saved || ed.format != ed.savedAs
+}
+
+// SetName changes the name shown, as after a save as
+func (ed *TextEditor) SetName(name string) {
+	ed.name = name
+	ed.showStatus()
+}
+
+// Save has the owner write the text, false if it wasn't
+func (ed *TextEditor) Save() bool {
+	if ed.OnSave == nil {
+		return false
+	}
+	text := ed.entry.Text
+	if err := ed.OnSave(text, ed.format); err != nil {
+		dialog.ShowError(err, ed.window)
+		return false
+	}
+	ed.saved = text
+	ed.savedAs = ed.format
+	ed.message = "saved"
+	ed.showStatus()
+	return true
+}
+
+// Undo goes back to the text before the last change
+func (ed *TextEditor) Undo() {
+	if len(ed.undo) < 1 {
+		return
+	}
+	s := ed.undo[len(ed.undo)-1]
+	ed.undo = ed.undo[:len(ed.undo)-1]
+	ed.redo = append(ed.redo, ed.snapshot())
+	ed.restore(s)
+}
+
+// Redo puts back the last change undone
+func (ed *TextEditor) Redo() {
+	if len(ed.redo) < 1 {
+		return
+	}
+	s := ed.redo[len(ed.redo)-1]
+	ed.redo = ed.redo[:len(ed.redo)-1]
+	ed.undo = append(ed.undo, ed.snapshot())
+	ed.restore(s)
+}
+
+func (ed *TextEditor) snapshot() snapshot {
+	return snapshot{text: ed.entry.Text, row: ed.entry.CursorRow, col: ed.entry.CursorColumn}
+}
+
+// changed is called for each change typed. A pause starts a new undo step.
+func (ed *TextEditor) changed(text string) {
+	if ed.restoring {
+		return
+	}
+	if len(ed.undo) < 1 || time.Since(ed.lastChange) > undoPause {
+		ed.pushUndo(snapshot{text: ed.text, row: ed.entry.CursorRow, col: ed.entry.CursorColumn})
+	}
+	ed.lastChange = time.Now()
+	ed.text = text
+	ed.redo = nil
+	ed.message = ""
+	ed.showStatus()
+}
+
+// edit replaces the whole text, as one undo step
+func (ed *TextEditor) edit(text string, row, col int) {
+	ed.pushUndo(ed.snapshot())
+	ed.redo = nil
+	ed.restore(snapshot{text: text, row: row, col: col})
+}
+
+// pushUndo adds a step, dropping the oldest over the limits
+func (ed *TextEditor) pushUndo(s snapshot) {
+	ed.undo = append(ed.undo, s)
+	size := 0
+	for _, u := range ed.undo {
+		size += len(u.text)
+	}
+	for len(ed.undo) > 1 && (len(ed.undo) > maxUndo || size > maxUndoBytes) {
+		size -= len(ed.undo[0].text)
+		ed.undo = ed.undo[1:]
+	}
+}
+
+func (ed *TextEditor) restore(s snapshot) {
+	ed.restoring = true
+	ed.entry.SetText(s.text)
+	ed.restoring = false
+	ed.text = s.text
+	ed.lastChange = time.Time{}
+	ed.entry.CursorRow, ed.entry.CursorColumn = textPosition(s.text, textOffset(s.text, s.row, s.col))
+	ed.entry.Refresh()
+	ed.showStatus()
+}
+
+func (ed *TextEditor) showStatus() {
+	title := ed.name
+	if ed.Dirty() {
+		title += " *"
+		ed.save.Enable()
+	} else {
+		ed.save.Disable()
+	}
+	ed.title.SetText(title)
+	if len(ed.undo) > 0 {
+		ed.undoButton.Enable()
+	} else {
+		ed.undoButton.Disable()
+	}
+	if len(ed.redo) > 0 {
+		ed.redoButton.Enable()
+	} else {
+		ed.redoButton.Disable()
+	}
+	status := fmt.Sprintf("Ln %d, Col %d", ed.entry.CursorRow+1, ed.entry.CursorColumn+1)
+	if ed.message != "" {
+		status += "  " + ed.message
+	}
+	ed.status.SetText(status)
+}
+
+// newFindBar - find and replace, shown by FIND... or Ctrl+F
+func (ed *TextEditor) newFindBar() *fyne.Container {
+	ed.find = widget.NewEntry()
+	ed.find.PlaceHolder = "find"
+	ed.find.OnSubmitted = func(_ string) {
+		ed.findNext(1)
+	}
+	ed.replace = widget.NewEntry()
+	ed.replace.PlaceHolder = "replace with"
+	ed.replace.OnSubmitted = func(_ string) {
+		ed.replaceNext()
+	}
+	ed.caseSensitive = widget.NewCheck("case", nil)
+	ed.isRegEx = widget.NewCheck("regexp", nil)
+	previous := widget.NewButtonWithIcon("", theme.MoveUpIcon(), func() {
+		ed.findNext(-1)
+	})
+	next := widget.NewButtonWithIcon("", theme.MoveDownIcon(), func() {
+		ed.findNext(1)
+	})
+	replace := widget.NewButton("REPLACE", func() {
+		ed.replaceNext()
+	})
+	all := widget.NewButton("ALL", func() {
+		ed.replaceAll()
+	})
+	hide := widget.NewButtonWithIcon("", theme.CancelIcon(), func() {
+		ed.findBar.Hide()
+		ed.window.Canvas().Focus(ed.entry)
+	})
+	buttons := container.NewHBox(ed.caseSensitive, ed.isRegEx, previous, next, replace, all, hide)
+	return container.NewBorder(nil, nil, nil, buttons, container.NewGridWithColumns(2, ed.find, ed.replace))
+}
+
+func (ed *TextEditor) showFind() {
+	if selected := ed.entry.SelectedText(); selected != "" && !strings.Contains(selected, "\n") {
+		ed.find.SetText(selected)
+	}
+	ed.findBar.Show()
+	ed.window.Canvas().Focus(ed.find)
+}
+
+// pattern to find, a literal unless regexp is checked
+func (ed *TextEditor) pattern() (*regexp.Regexp, error) {
+	find := ed.find.Text
+	if find == "" {
+		return nil, errors.New("nothing to find")
+	}
+	if !ed.isRegEx.Checked {
+		find = regexp.QuoteMeta(find)
+	}
+	if !ed.caseSensitive.Checked {
+		find = "(?i)" + find
+	}
+	re, err := regexp.Compile(find)
+	if err != nil {
+		return nil, errors.New("invalid regular expression")
+	}
+	return re, nil
+}
+
+// matches of the pattern, without empty ones
+func (ed *TextEditor) matches(re *regexp.Regexp, text string) [][]int {
+	found := make([][]int, 0)
+	for _, m := range re.FindAllStringSubmatchIndex(text, -1) {
+		if m[1] > m[0] {
+			found = append(found, m)
+		}
+	}
+	return found
+}
+
+// selection is the byte offsets of the selected text, if the cursor is at its end
+func (ed *TextEditor) selection(text string) (int, int) {
+	end := textOffset(text, ed.entry.CursorRow, ed.entry.CursorColumn)
+	selected := ed.entry.SelectedText()
+	if start := end - len(selected); selected != "" && start >= 0 && text[start:end] == selected {
+		return start, end
+	}
+	return end, end
+}
+
+// findNext selects the next (1) or previous (-1) match, from the cursor, wrapping around
+func (ed *TextEditor) findNext(step int) {
+	re, err := ed.pattern()
+	if err != nil {
+		dialog.ShowError(err, ed.window)
+		return
+	}
+	text := ed.entry.Text
+	found := ed.matches(re, text)
+	if len(found) < 1 {
+		ed.message = fmt.Sprintf("'%s' not found", ed.find.Text)
+		ed.showStatus()
+		return
+	}
+	start, end := ed.selection(text)
+	n := 0
+	if step > 0 {
+		for n < len(found) && found[n][0] < end {
+			n++
+		}
+		if n == len(found) {
+			n = 0
+		}
+	} else {
+		n = len(found) - 1
+		for n >= 0 && found[n][0] >= start {
+			n--
+		}
+		if n < 0 {
+			n = len(found) - 1
+		}
+	}
+	ed.selectText(text, found[n][0], found[n][1])
+	ed.message = fmt.Sprintf("match %d of %d", n+1, len(found))
+	ed.showStatus()
+}
+
+// replaceNext replaces the selected match, and finds the next
+func (ed *TextEditor) replaceNext() {
+	re, err := ed.pattern()
+	if err != nil {
+		dialog.ShowError(err, ed.window)
+		return
+	}
+	text := ed.entry.Text
+	start, end := ed.selection(text)
+	for _, m := range ed.matches(re, text) {
+		if m[0] == start && m[1] == end {
+			replacement := ed.replace.Text
+			if ed.isRegEx.Checked {
+				replacement = string(re.ExpandString(nil, replacement, text, m))
+			}
+			text = text[:start] + replacement + text[end:]
+			row, col := textPosition(text, start+len(replacement))
+			ed.edit(text, row, col)
+			break
+		}
+	}
+	ed.findNext(1)
+}
+
+func (ed *TextEditor) replaceAll() {
+	re, err := ed.pattern()
+	if err != nil {
+		dialog.ShowError(err, ed.window)
+		return
+	}
+	text := ed.entry.Text
+	count := len(ed.matches(re, text))
+	if count > 0 {
+		if ed.isRegEx.Checked {
+			text = re.ReplaceAllString(text, ed.replace.Text)
+		} else {
+			text = re.ReplaceAllLiteralString(text, ed.replace.Text)
+		}
+		ed.edit(text, ed.entry.CursorRow, ed.entry.CursorColumn)
+	}
+	ed.message = fmt.Sprintf("%d replaced", count)
+	ed.showStatus()
+}
+
+// selectText selects from byte offset start to end, as shift + arrow keys would
+func (ed *TextEditor) selectText(text string, start, end int) {
+	e := ed.entry
+	if e.SelectedText() != "" {
+		e.TypedKey(&fyne.KeyEvent{Name: fyne.KeyLeft}) // drop the selection
+	}
+	e.CursorRow, e.CursorColumn = textPosition(text, start)
+	shift := &fyne.KeyEvent{Name: desktop.KeyShiftLeft}
+	e.KeyDown(shift)
+	for n := utf8.RuneCountInString(text[start:end]); n > 0; n-- {
+		e.TypedKey(&fyne.KeyEvent{Name: fyne.KeyRight})
+	}
+	e.KeyUp(shift)
+	e.Refresh()
+}
+
+// textOffset is the byte offset of a row and (rune) column, within the text
+func textOffset(text string, row, col int) int {
+	offset := 0
+	for ; row > 0; row-- {
+		nl := strings.IndexByte(text[offset:], '\n')
+		if nl < 0 {
+			return len(text)
+		}
+		offset += nl + 1
+	}
+	for ; col > 0 && offset < len(text) && text[offset] != '\n'; col-- {
+		_, size := utf8.DecodeRuneInString(text[offset:])
+		offset += size
+	}
+	return offset
+}
+
+// textPosition is the row and (rune) column of a byte offset
+func textPosition(text string, offset int) (int, int) {
+	offset = min(offset, len(text))
+	line := strings.LastIndexByte(text[:offset], '\n') + 1
+	return strings.Count(text[:offset], "\n"), utf8.RuneCountInString(text[line:offset])
+}
+
+// editorEntry is a monospace, multi line Entry, with the editor's shortcuts
+type editorEntry struct {
+	widget.Entry
+	editor *TextEditor
+}
+
+func newEditorEntry(editor *TextEditor) *editorEntry {
+	e := &editorEntry{editor: editor}
+	e.MultiLine = true
+	e.Wrapping = fyne.TextWrapOff
+	e.TextStyle = fyne.TextStyle{Monospace: true}
+	e.ExtendBaseWidget(e)
+	return e
+}
+
+func (e *editorEntry) TypedShortcut(shortcut fyne.Shortcut) {
+	switch s := shortcut.(type) {
+	case *fyne.ShortcutUndo:
+		e.editor.Undo()
+		return
+	case *fyne.ShortcutRedo:
+		e.editor.Redo()
+		return
+	case *desktop.CustomShortcut:
+		if s.Modifier == fyne.KeyModifierShortcutDefault {
+			switch s.KeyName {
+			case fyne.KeyF:
+				e.editor.showFind()
+				return
+			case fyne.KeyS:
+				e.editor.Save()
+				return
+			}
+		}
+	}
+	e.Entry.TypedShortcut(shortcut)
+}
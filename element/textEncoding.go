@@ -0,0 +1,142 @@
+package element
+
+/*
+
+  File:    textEncoding.go
+  Author:  Bob Shofner
+
+  MIT License - https://opensource.org/license/mit/
+
+  This permission notice shall be included in all copies
+    or substantial portions of the Software.
+*/
+/*
+  Description: character encodings of the TextViewer text.
+  Single byte encodings (and Shift-JIS) are decoded a line at a time.
+  UTF-16 has 2 byte new lines, so it is converted to UTF-8 in memory.
+*/
+
+import (
+	"bytes"
+	"fman/fileutil"
+	"fyne.io/fyne/v2"
+	"io"
+	"log"
+)
+
+// setEncoding shows the text in an encoding, one of fileutil.Encodings
+func (v *TextViewer) setEncoding(name string) {
+	enc := fileutil.TextEncoding(name)
+	if enc == nil {
+		return
+	}
+	v.setting = true
+	v.encodings.SetSelected(name)
+	v.setting = false
+
+	// the matches are for other text
+	v.mu.Lock()
+	v.generation++
+	v.matches = nil
+	v.mu.Unlock()
+	v.searchFor = ""
+	v.searching = false
+	v.current = -1
+
+	first := v.encoding == ""
+	v.encoding = name
+	v.decoder = nil
+	if name != fileutil.UTF8 {
+		v.decoder = enc
+	}
+	if name == fileutil.UTF16LE || name == fileutil.UTF16BE {
+		v.decoder = nil
+		v.converted = true
+		v.followToggle.SetChecked(false)
+		v.followToggle.Disable()
+		skip := int64(0)
+		if v.bomEncoding == name {
+			skip = int64(v.bom)
+		}
+		v.reindex(func() (io.ReaderAt, int64) {
+			text := io.NewSectionReader(v.source, skip, v.sourceLength-skip)
+			content, err := io.ReadAll(enc.NewDecoder().Reader(text))
+			if err != nil {
+				log.Printf("Error %s decoding %s: %s\n", err, name, v.name)
+			}
+			return bytes.NewReader(content), int64(len(content))
+		})
+		return
+	}
+	v.followToggle.Enable()
+	if first || v.converted {
+		v.converted = false
+		v.reindex(func() (io.ReaderAt, int64) {
+			return v.source, v.sourceLength
+		})
+		return
+	}
+	v.showStatus()
+	v.render()
+}
+
+// reindex replaces the text being viewed, indexing it in the background
+func (v *TextViewer) reindex(load func() (io.ReaderAt, int64)) {
+	v.indexed = false
+	v.showStatus()
+	v.mu.Lock()
+	v.reindexed++
+	reindexed := v.reindexed
+	v.mu.Unlock()
+	go func() {
+		v.indexing.Lock() // unlocked by buildIndex
+		v.mu.Lock()
+		stale := v.reindexed != reindexed
+		v.mu.Unlock()
+		if stale {
+			v.indexing.Unlock()
+			return
+		}
+		reader, length := load()
+		v.index.reset(reader)
+		v.mu.Lock()
+		v.length = length
+		v.mu.Unlock()
+		fyne.Do(func() {
+			v.setTop(v.top)
+		})
+		v.buildIndex()
+	}()
+}
+
+// lineDecoder converts a (0 based) line of the text to UTF-8
+func (v *TextViewer) lineDecoder() func(row int, text []byte) []byte {
+	bom := 0
+	if !v.converted && v.bomEncoding == v.encoding {
+		bom = v.bom
+	}
+	decoder := v.decoder
+	return func(row int, text []byte) []byte {
+		if row == 0 && bom > 0 && len(text) >= bom {
+			text = text[bom:]
+		}
+		if decoder == nil {
+			return text
+		}
+		decoded, err := decoder.NewDecoder().Bytes(text)
+		if err != nil {
+			return text
+		}
+		return decoded
+	}
+}
+
+// lines reads and decodes n lines, from (0 based) line first
+func (v *TextViewer) lines(first, n int) [][]byte {
+	decode := v.lineDecoder()
+	lines := v.index.read(first, n)
+	for i := range lines {
+		lines[i] = decode(first+i, lines[i])
+	}
+	return lines
+}
@@ -0,0 +1,185 @@
+package element
+
+/*
+
+  File:    textFollow.go
+  Author:  Bob Shofner
+
+  MIT License - https://opensource.org/license/mit/
+
+  This permission notice shall be included in all copies
+    or substantial portions of the Software.
+*/
+/*
+  Description: follow (tail -f) a file in the TextViewer.
+  Appends are indexed as they arrive, a truncated file is re-read,
+  and a rotated file (a new file with the same name) is reopened.
+*/
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/theme"
+	"github.com/fsnotify/fsnotify"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+)
+
+// the file is also checked this often, for file systems without notification
+var followPollInterval = time.Second
+
+// HighlightRule colors the text of lines matching Pattern
+type HighlightRule struct {
+	Pattern *regexp.Regexp
+	Color   fyne.ThemeColorName
+}
+
+var DefaultHighlightRules = []HighlightRule{
+	{Pattern: regexp.MustCompile(`\b(ERROR|FATAL|PANIC)\b`), Color: theme.ColorNameError},
+	{Pattern: regexp.MustCompile(`\bWARN(ING)?\b`), Color: theme.ColorNameWarning},
+}
+
+// SetFollow allows the file at path to be followed, showing the follow toggle
+func (v *TextViewer) SetFollow(path string) {
+	if v.file == nil {
+		return // not paging through a file
+	}
+	v.path = path
+	v.followToggle.Show()
+}
+
+// SetHighlightRules replaces the line highlight rules
+func (v *TextViewer) SetHighlightRules(rules []HighlightRule) {
+	v.rules = rules
+	v.render()
+}
+
+// setFollowing starts or stops watching the file
+func (v *TextViewer) setFollowing(on bool) {
+	if on == (v.stopFollow != nil) {
+		return
+	}
+	if !on {
+		close(v.stopFollow)
+		v.stopFollow = nil
+		v.showStatus()
+		return
+	}
+	v.stopFollow = make(chan struct{})
+	go v.follow(v.stopFollow)
+	v.setTop(v.lastTop())
+	v.showStatus()
+}
+
+// follow watches the directory of the file (to see rotation), until stop is closed
+func (v *TextViewer) follow(stop chan struct{}) {
+	var events chan fsnotify.Event
+	watcher, err := fsnotify.NewWatcher()
+	if err == nil {
+		if err = watcher.Add(filepath.Dir(v.path)); err != nil {
+			log.Printf("Error %s watching: %s\n", err, v.path)
+		}
+		events = watcher.Events
+		defer func() {
+			_ = watcher.Close()
+		}()
+	}
+	ticker := time.NewTicker(followPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		case event, ok := <-events:
+			if !ok {
+				events = nil
+				continue
+			}
+			if filepath.Clean(event.Name) != filepath.Clean(v.path) {
+				continue
+			}
+		}
+		v.followCheck()
+	}
+}
+
+// followCheck indexes any change to the followed file
+func (v *TextViewer) followCheck() {
+	v.indexing.Lock()
+	defer v.indexing.Unlock()
+	if v.isClosed() {
+		return
+	}
+	info, err := os.Stat(v.path)
+	if err != nil {
+		return // rotated away, wait for the new file
+	}
+	v.mu.Lock()
+	file := v.file
+	v.mu.Unlock()
+	current, err := file.Stat()
+	restart := false
+	if err != nil || !os.SameFile(info, current) {
+		reopened, err := os.Open(v.path)
+		if err != nil {
+			return
+		}
+		v.mu.Lock()
+		if v.owned != nil {
+			_ = v.owned.Close()
+		}
+		v.owned = reopened
+		v.file = reopened
+		v.mu.Unlock()
+		v.index.reset(reopened)
+		restart = true
+	} else {
+		info = current
+	}
+	size := info.Size()
+	if size < v.index.size() {
+		restart = true
+	}
+	if size == v.index.size() && !restart {
+		return
+	}
+	if restart { // the lines are not the same, end a search of them
+		v.mu.Lock()
+		v.generation++
+		v.matches = nil
+		v.mu.Unlock()
+	}
+	if err = v.index.extend(size, v.isClosed, nil); err != nil {
+		return
+	}
+	v.mu.Lock()
+	v.length = size
+	v.mu.Unlock()
+	fyne.Do(func() {
+		if restart {
+			v.searchFor = ""
+			v.searching = false
+			v.current = -1
+		}
+		if v.stopFollow != nil {
+			v.setTop(v.lastTop())
+		}
+		v.update()
+	})
+}
+
+// lineColor is the color of the first rule matching a line, nil is none
+func (v *TextViewer) lineColor(text []byte) *HighlightRule {
+	if !v.highlight {
+		return nil
+	}
+	for i := range v.rules {
+		if v.rules[i].Pattern.Match(text) {
+			return &v.rules[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+package element
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"math"
+)
+
+/*
+
+  File:    textPage.go
+  Author:  Bob Shofner
+
+  MIT License - https://opensource.org/license/mit/
+
+  This permission notice shall be included in all copies
+    or substantial portions of the Software.
+
+*/
+/*
+  Description: a TextGrid sized to show one page (window) of a larger text.
+  The owner fills the rows, and is told of resizing, scrolling and keys.
+*/
+
+var _ fyne.Scrollable = (*textPage)(nil)
+var _ fyne.Focusable = (*textPage)(nil)
+var _ fyne.Tappable = (*textPage)(nil)
+var _ fyne.Draggable = (*textPage)(nil)
+var _ fyne.Shortcutable = (*textPage)(nil)
+
+type textPage struct {
+	widget.BaseWidget
+	grid     *widget.TextGrid
+	rows     int
+	cols     int
+	dy       float32 // partial row scrolled
+	dx       float32
+	OnResize func(rows, cols int)
+	OnScroll func(rows, cols int)
+	OnKey    func(*fyne.KeyEvent)
+	OnRune   func(rune)
+	OnTap    func(row, col int)
+	OnDrag   func(row, col int) // row and col may be outside the page
+	OnCopy   func()
+}
+
+func newTextPage() *textPage {
+	p := &textPage{grid: widget.NewTextGrid()}
+	p.ExtendBaseWidget(p)
+	return p
+}
+
+// cellSize is the size of a single character, as used by TextGrid
+func (p *textPage) cellSize() fyne.Size {
+	size := fyne.MeasureText("M", theme.TextSize(), fyne.TextStyle{Monospace: true})
+	return fyne.NewSize(float32(math.Round(float64(size.Width))), float32(math.Round(float64(size.Height))))
+}
+
+func (p *textPage) CreateRenderer() fyne.WidgetRenderer {
+	return &textPageRenderer{page: p, objects: []fyne.CanvasObject{p.grid}}
+}
+
+func (p *textPage) Scrolled(e *fyne.ScrollEvent) {
+	cell := p.cellSize()
+	p.dy -= e.Scrolled.DY
+	p.dx -= e.Scrolled.DX
+	rows := int(p.dy / cell.Height)
+	cols := int(p.dx / cell.Width)
+	p.dy -= float32(rows) * cell.Height
+	p.dx -= float32(cols) * cell.Width
+	if (rows != 0 || cols != 0) && p.OnScroll != nil {
+		p.OnScroll(rows, cols)
+	}
+}
+
+func (p *textPage) Tapped(e *fyne.PointEvent) {
+	if c := fyne.CurrentApp().Driver().CanvasForObject(p); c != nil {
+		c.Focus(p)
+	}
+	if p.OnTap != nil {
+		cell := p.cellSize()
+		p.OnTap(int(e.Position.Y/cell.Height), int(e.Position.X/cell.Width))
+	}
+}
+
+func (p *textPage) Dragged(e *fyne.DragEvent) {
+	if p.OnDrag != nil {
+		cell := p.cellSize()
+		row := e.Position.Y / cell.Height
+		if row < 0 {
+			row-- // truncation is toward zero
+		}
+		p.OnDrag(int(row), int(e.Position.X/cell.Width))
+	}
+}
+func (p *textPage) DragEnd() {
+}
+
+func (p *textPage) TypedShortcut(s fyne.Shortcut) {
+	if _, ok := s.(*fyne.ShortcutCopy); ok && p.OnCopy != nil {
+		p.OnCopy()
+	}
+}
+
+func (p *textPage) FocusGained() {
+}
+func (p *textPage) FocusLost() {
+}
+func (p *textPage) TypedRune(r rune) {
+	if p.OnRune != nil {
+		p.OnRune(r)
+	}
+}
+func (p *textPage) TypedKey(key *fyne.KeyEvent) {
+	if p.OnKey != nil {
+		p.OnKey(key)
+	}
+}
+
+type textPageRenderer struct {
+	page    *textPage
+	objects []fyne.CanvasObject
+}
+
+func (r *textPageRenderer) Destroy() {
+}
+func (r *textPageRenderer) Layout(size fyne.Size) {
+	r.page.grid.Resize(size)
+	cell := r.page.cellSize()
+	rows := int(size.Height / cell.Height)
+	cols := int(size.Width / cell.Width)
+	if rows != r.page.rows || cols != r.page.cols {
+		r.page.rows = rows
+		r.page.cols = cols
+		if r.page.OnResize != nil {
+			r.page.OnResize(rows, cols)
+		}
+	}
+}
+func (r *textPageRenderer) MinSize() fyne.Size {
+	cell := r.page.cellSize()
+	return fyne.NewSize(cell.Width*20, cell.Height*3)
+}
+func (r *textPageRenderer) Objects() []fyne.CanvasObject {
+	return r.objects
+}
+func (r *textPageRenderer) Refresh() {
+	r.page.grid.Refresh()
+}
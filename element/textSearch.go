@@ -0,0 +1,264 @@
+package element
+
+/*
+
+  File:    textSearch.go
+  Author:  Bob Shofner
+
+  MIT License - https://opensource.org/license/mit/
+
+  This permission notice shall be included in all copies
+    or substantial portions of the Software.
+*/
+/*
+  Description: search the TextViewer file in the background,
+  and step through the matches. Also go to a line number.
+*/
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// searching stops after this many matches
+var maxMatches = 1000000
+
+// getSearchParameters - a form dialog to get user search criteria
+func (v *TextViewer) getSearchParameters(window fyne.Window, cb func(s searcher)) {
+	var params searcher
+
+	var items = make([]*widget.FormItem, 0)
+
+	entry := widget.NewEntry()
+	entry.PlaceHolder = "3 minimum"
+	find := widget.NewFormItem("Text to find. (minimum 3 chars)", entry)
+	isRegExp := widget.NewCheck("", func(b bool) {
+		params.isRegEx = b
+	})
+	regExp := widget.NewFormItem("Regular Expession?", isRegExp)
+	isCaseSensitive := widget.NewCheck("", func(b bool) {
+		params.caseSensitive = b
+	})
+	caseSensitive := widget.NewFormItem("Case Sensitive?", isCaseSensitive)
+	spacer := widget.NewFormItem("", widget.NewLabel("                           "))
+
+	items = append(items, spacer)
+	items = append(items, find)
+	items = append(items, regExp)
+	items = append(items, caseSensitive)
+
+	entry.Validator = func(str string) error {
+		if len(str) < 3 {
+			return errors.New("find string must be > 2 characters")
+		}
+		if params.isRegEx { // Check doesn't support validation
+			re, err := regexp.Compile(str)
+			if err != nil || re == nil {
+				return errors.New("invalid regular expression")
+			}
+		}
+		return nil
+	}
+
+	dialog.ShowForm("Search Criteria for "+v.name, "search", "cancel", items, func(b bool) {
+		if b {
+			params.find = entry.Text
+			cb(params)
+		}
+	}, window)
+
+	return
+}
+
+// startSearch clears the current matches, and searches in the background
+func (v *TextViewer) startSearch(s searcher) {
+	v.mu.Lock()
+	v.generation++
+	generation := v.generation
+	v.matches = nil
+	v.mu.Unlock()
+	v.marked = -1
+	v.current = -1
+	v.searchFor = s.find
+	v.searching = true
+	v.showStatus()
+	v.render()
+	go v.search(s, generation, v.lineDecoder())
+}
+
+// search reads the whole file, a line at a time, collecting every match
+func (v *TextViewer) search(s searcher, generation int, decode func(row int, text []byte) []byte) {
+	find := s.find
+	var re *regexp.Regexp
+	if !s.isRegEx && !s.caseSensitive {
+		find = regexp.QuoteMeta(find)
+	}
+	if s.isRegEx || !s.caseSensitive {
+		if !s.caseSensitive {
+			find = "(?i)" + find
+		}
+		re = regexp.MustCompile(find)
+	}
+	stale := func() bool {
+		v.mu.Lock()
+		defer v.mu.Unlock()
+		return v.generation != generation
+	}
+
+	v.mu.Lock()
+	length := v.length
+	v.mu.Unlock()
+	r := bufio.NewReaderSize(io.NewSectionReader(v.index.source(), 0, length), 64*1024)
+	count := 0
+	last := time.Now()
+	for row := 0; count < maxMatches; row++ {
+		text, err := readLine(r)
+		if err != nil && len(text) == 0 {
+			break
+		}
+		text = decode(row, text)
+		var found []match
+		if re != nil {
+			for _, cols := range re.FindAllIndex(text, -1) {
+				if cols[1] > cols[0] {
+					found = append(found, match{row: row, col1: cols[0], col2: cols[1]})
+				}
+			}
+		} else {
+			for col := 0; ; {
+				i := bytes.Index(text[col:], []byte(find))
+				if i < 0 {
+					break
+				}
+				found = append(found, match{row: row, col1: col + i, col2: col + i + len(find)})
+				col += i + len(find)
+			}
+		}
+		if len(found) > 0 {
+			v.mu.Lock()
+			if v.generation != generation {
+				v.mu.Unlock()
+				return
+			}
+			v.matches = append(v.matches, found...)
+			v.mu.Unlock()
+			if count == 0 {
+				fyne.Do(func() {
+					if !stale() && v.current < 0 {
+						v.showMatch(0)
+					}
+				})
+			}
+			count += len(found)
+		}
+		if time.Since(last) > viewerUpdateInterval {
+			last = time.Now()
+			if stale() {
+				return
+			}
+			fyne.Do(func() {
+				if !stale() {
+					v.showStatus()
+					v.render()
+				}
+			})
+		}
+		if err != nil {
+			break
+		}
+	}
+	fyne.Do(func() {
+		if stale() {
+			return
+		}
+		v.searching = false
+		v.showStatus()
+		v.render()
+		if count < 1 {
+			dialog.ShowInformation("No Matches", fmt.Sprintf("for '%s'", s.find), v.window)
+		}
+	})
+}
+
+// nextMatch steps forward (1) or back (-1) through the matches
+func (v *TextViewer) nextMatch(step int) {
+	v.mu.Lock()
+	count := len(v.matches)
+	v.mu.Unlock()
+	if count == 0 {
+		return
+	}
+	next := v.current + step
+	if v.current < 0 {
+		next = 0
+	}
+	if next < 0 || next >= count {
+		return
+	}
+	v.showMatch(next)
+}
+
+// showMatch scrolls a match into view, and makes it current
+func (v *TextViewer) showMatch(n int) {
+	v.mu.Lock()
+	if n >= len(v.matches) {
+		v.mu.Unlock()
+		return
+	}
+	m := v.matches[n]
+	v.mu.Unlock()
+	v.current = n
+
+	// center the line, if it is not on the page
+	if m.row < v.top || m.row >= v.top+v.page.rows {
+		v.pending = m.row - v.page.rows/2
+		if v.pending < 0 {
+			v.pending = 0
+		}
+	}
+	// and show the columns
+	if lines := v.lines(m.row, 1); len(lines) == 1 {
+		_, at := expandTabs(lines[0], v.tabSize)
+		cols := v.page.cols - v.gutter
+		c1, c2 := at[min(m.col1, len(lines[0]))], at[min(m.col2, len(lines[0]))]
+		if c1 < v.left || c2 > v.left+cols {
+			v.setLeft(c1 - cols/4)
+		}
+	}
+	if v.pending >= 0 && v.pending < v.index.count() {
+		v.setTop(v.pending)
+		v.pending = -1
+	} else {
+		v.render()
+	}
+	v.showStatus()
+}
+
+// getLine - a form dialog for a (1 based) line number to go to
+func (v *TextViewer) getLine() {
+	entry := widget.NewEntry()
+	entry.PlaceHolder = fmt.Sprintf("1 to %d", v.index.count())
+	entry.Validator = func(str string) error {
+		if n, err := strconv.Atoi(strings.TrimSpace(str)); err != nil || n < 1 {
+			return errors.New("not a line number")
+		}
+		return nil
+	}
+	items := []*widget.FormItem{widget.NewFormItem("Line", entry)}
+	dialog.ShowForm("Go To Line in "+v.name, "go", "cancel", items, func(b bool) {
+		if b {
+			n, _ := strconv.Atoi(strings.TrimSpace(entry.Text))
+			v.ShowRow(n - 1)
+		}
+	}, v.window)
+}
@@ -12,29 +12,84 @@ package element
 */
 /*
   Description: simple text viewer with search ability.
+  Files are not read into memory. An index of line starts is built in the
+  background, and only the lines visible in the window are read and shown.
 */
 
 import (
+	"bytes"
 	"errors"
+	"fman/fileutil"
 	"fmt"
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
-	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/layout"
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
+	"golang.org/x/text/encoding"
 	"io"
 	"log"
-	"regexp"
-	"strings"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+	"unicode/utf8"
 )
 
+// how often the view is updated while indexing or searching
+var viewerUpdateInterval = 100 * time.Millisecond
+
 type TextViewer struct {
-	Content *fyne.Container
-	Err     error
-	name    string
-	view    *widget.TextGrid
-	nRows   int
+	Content      *fyne.Container
+	Err          error
+	name         string
+	window       fyne.Window
+	page         *textPage
+	bar          *widget.Slider
+	status       *widget.Label
+	index        *lineIndex
+	length       int64
+	tabSize      int
+	lineNumbers  bool
+	whiteSpace   bool
+	indexed      bool // the index is complete
+	top          int  // first line shown
+	left         int  // first column shown
+	marked       int  // highlighted line, -1 is none
+	pending      int  // line to show once it is indexed, -1 is none
+	settingBar   bool
+	searchFor    string // the current search, "" is none
+	searching    bool
+	current      int // the match shown by next / previous, -1 is none
+	anchor       position
+	selected     position // selected text is between anchor and selected
+	shown        [][]int  // byte to cell maps of the rendered lines
+	gutter       int      // cells used by line numbers
+	path         string   // of a file that may be followed
+	followToggle *widget.Check
+	stopFollow   chan struct{} // closed to stop following
+	highlight    bool
+	rules        []HighlightRule
+	lexer        SyntaxLexer
+	indexing     sync.Mutex        // one goroutine at a time extends the index
+	encoding     string            // of the text, one of fileutil.Encodings
+	decoder      encoding.Encoding // of each line, nil for UTF-8 (or converted)
+	bom          int               // length of the byte order mark
+	bomEncoding  string            // the encoding the bom is for
+	converted    bool              // UTF-16 was converted to UTF-8 in memory
+	source       io.ReaderAt
+	sourceLength int64
+	encodings    *widget.Select
+	setting      bool // a widget value is being set, not changed by the user
+
+	mu         sync.Mutex // guards the fields below, used by background goroutines
+	matches    []match    // ordered by row
+	generation int        // a new search (or Close) ends the current one
+	reindexed  int        // a new index ends building the current one
+	closed     bool
+	file       *os.File // being paged through, nil if read into memory
+	owned      *os.File // opened when following a rotated file
 }
 type searcher struct {
 	find          string
@@ -43,50 +98,88 @@ type searcher struct {
 }
 type match struct {
 	row  int
-	col1 int
+	col1 int // byte offsets in the line
 	col2 int
 }
 
+// position of a byte in the text
+type position struct {
+	row int
+	col int
+}
+
+func (p position) before(o position) bool {
+	return p.row < o.row || (p.row == o.row && p.col < o.col)
+}
+
 func NewTextViewer(window fyne.Window, name string, reader io.Reader, buttons []*widget.Button,
 	tabSize int) *TextViewer {
 
 	viewer := TextViewer{
-		Content: nil,
-		name:    name,
-		view:    widget.NewTextGrid(),
+		Content:     nil,
+		name:        name,
+		window:      window,
+		page:        newTextPage(),
+		status:      widget.NewLabel(""),
+		tabSize:     tabSize,
+		lineNumbers: true,
+		marked:      -1,
+		pending:     -1,
+		current:     -1,
+		highlight:   true,
+		rules:       DefaultHighlightRules,
+		lexer:       LexerFor(name),
+	}
+	if viewer.tabSize < 1 {
+		viewer.tabSize = 4
 	}
-	scroll := container.NewScroll(viewer.view)
 
 	title := widget.NewLabel(name)
 	title.Importance = widget.HighImportance
 	title.Alignment = fyne.TextAlignTrailing
 
-	buttonBar := container.NewHBox(layout.NewSpacer())
 	lineNumbers := widget.NewCheck("line #s", nil)
 	whiteSpace := widget.NewCheck("white space", nil)
-	buttonBar = container.NewHBox()
+	buttonBar := container.NewHBox()
 	buttonBar.Objects = append(buttonBar.Objects, lineNumbers)
 	buttonBar.Objects = append(buttonBar.Objects, whiteSpace)
-	var matches []match
 	find := widget.NewButtonWithIcon("SEARCH...", theme.SearchIcon(), func() {
-		n := 0
-		for _, match := range matches {
-			viewer.highLight(match.row, match.col1, match.col2, false)
-			n++
-		}
-		if n > 0 {
-			viewer.view.Refresh()
-		}
 		viewer.getSearchParameters(window, func(s searcher) {
-			found := viewer.markFinds(s)
-			matches = found
-			if len(matches) < 1 {
-				dialog.ShowInformation("No Matches", fmt.Sprintf("for '%s'", s.find), window)
-			}
-			viewer.view.Refresh()
+			viewer.startSearch(s)
 		})
 	})
-	buttonBar.Objects = append(buttonBar.Objects, find)
+	previous := widget.NewButtonWithIcon("", theme.MoveUpIcon(), func() {
+		viewer.nextMatch(-1)
+	})
+	next := widget.NewButtonWithIcon("", theme.MoveDownIcon(), func() {
+		viewer.nextMatch(1)
+	})
+	goTo := widget.NewButton("GOTO...", func() {
+		viewer.getLine()
+	})
+	copySelected := widget.NewButtonWithIcon("", theme.ContentCopyIcon(), func() {
+		viewer.copySelection()
+	})
+	buttonBar.Objects = append(buttonBar.Objects, find, previous, next, goTo, copySelected)
+	highlight := widget.NewCheck("highlight", nil)
+	highlight.SetChecked(viewer.highlight)
+	highlight.OnChanged = func(on bool) {
+		viewer.highlight = on
+		viewer.render()
+	}
+	buttonBar.Objects = append(buttonBar.Objects, highlight)
+	viewer.followToggle = widget.NewCheck("follow", func(on bool) {
+		viewer.setFollowing(on)
+	})
+	viewer.followToggle.Hide() // until SetFollow
+	buttonBar.Objects = append(buttonBar.Objects, viewer.followToggle)
+	viewer.encodings = widget.NewSelect(fileutil.Encodings, func(name string) {
+		if !viewer.setting {
+			viewer.setEncoding(name)
+		}
+	})
+	buttonBar.Objects = append(buttonBar.Objects, viewer.encodings)
+	buttonBar.Objects = append(buttonBar.Objects, viewer.status)
 
 	// add in any user buttons
 	if len(buttons) > 0 {
@@ -96,143 +189,455 @@ func NewTextViewer(window fyne.Window, name string, reader io.Reader, buttons []
 		}
 	}
 
-	viewer.view.ShowLineNumbers = true
-	lineNumbers.SetChecked(viewer.view.ShowLineNumbers)
+	lineNumbers.SetChecked(viewer.lineNumbers)
 	lineNumbers.OnChanged = func(show bool) {
-		viewer.view.ShowLineNumbers = show
-		viewer.view.Refresh()
+		viewer.lineNumbers = show
+		viewer.render()
 	}
-	viewer.view.ShowWhitespace = false // use spaces in place of \t, \r,...
-	whiteSpace.SetChecked(viewer.view.ShowWhitespace)
+	viewer.page.grid.ShowWhitespace = false // use spaces in place of \t, \r,...
+	whiteSpace.SetChecked(viewer.whiteSpace)
 	whiteSpace.OnChanged = func(show bool) {
-		viewer.view.ShowWhitespace = show
-		viewer.view.Refresh()
+		viewer.whiteSpace = show
+		viewer.page.grid.ShowWhitespace = show
+		viewer.render()
 	}
-	viewer.view.TabWidth = tabSize
+	viewer.page.grid.TabWidth = viewer.tabSize
 
-	viewer.Content = container.NewBorder(title, buttonBar, nil, nil, scroll)
-
-	content, err := io.ReadAll(reader)
-	if err != nil {
-		log.Printf("Error %s reading: %s\n", err, name)
-		viewer.Err = err
-		content = []byte(fmt.Sprintf("io.ReadAll failure on %s\n", name))
+	viewer.bar = widget.NewSlider(0, 1)
+	viewer.bar.Orientation = widget.Vertical
+	viewer.bar.Step = 1
+	viewer.bar.OnChanged = func(value float64) {
+		if !viewer.settingBar {
+			viewer.setTop(int(viewer.bar.Max - value))
+		}
 	}
-	viewer.view.SetText(string(content))
-	viewer.nRows = len(viewer.view.Rows) - 1 // skip empty last line
-
-	// last line
-	line := viewer.getRowAsString(viewer.nRows - 1)
-	log.Println("name", name, "rows:", viewer.nRows, ", last:", line,
-		"len", len(line))
+	viewer.page.OnResize = func(_, _ int) {
+		viewer.setTop(viewer.top)
+	}
+	viewer.page.OnScroll = func(rows, cols int) {
+		viewer.setLeft(viewer.left + cols)
+		viewer.setTop(viewer.top + rows)
+	}
+	viewer.page.OnKey = viewer.typedKey
+	viewer.page.OnTap = func(row, col int) {
+		viewer.anchor = viewer.positionAt(row, col)
+		viewer.selected = viewer.anchor
+		viewer.render()
+	}
+	viewer.page.OnDrag = viewer.dragged
+	viewer.page.OnCopy = viewer.copySelection
 
-	return &viewer
-}
+	viewer.Content = container.NewBorder(title, buttonBar, nil, viewer.bar, viewer.page)
 
-// highLight sets normal or reverse colors for a range of columns on a roe
-func (v *TextViewer) highLight(row, col1, col2 int, rev bool) {
-	tgs := &widget.CustomTextGridStyle{
-		TextStyle: widget.TextGridStyleDefault.Style(),
-		BGColor:   theme.Color(theme.ColorNameBackground),
-		FGColor:   theme.Color(theme.ColorNameForeground),
+	// page through a file, anything else is read into memory
+	var readerAt io.ReaderAt
+	if file, ok := reader.(*os.File); ok {
+		if info, err := file.Stat(); err == nil && info.Mode().IsRegular() {
+			readerAt = file
+			viewer.file = file
+			viewer.length = info.Size()
+		}
 	}
-	if rev {
-		tgs.BGColor = theme.Color(theme.ColorNameForeground)
-		tgs.FGColor = theme.Color(theme.ColorNameBackground)
+	if readerAt == nil {
+		content, err := io.ReadAll(reader)
+		if err != nil {
+			log.Printf("Error %s reading: %s\n", err, name)
+			viewer.Err = err
+			content = []byte(fmt.Sprintf("io.ReadAll failure on %s\n", name))
+		}
+		readerAt = bytes.NewReader(content)
+		viewer.length = int64(len(content))
 	}
-	v.view.SetStyleRange(row, col1, row, col2, tgs)
+	viewer.source = readerAt
+	viewer.sourceLength = viewer.length
+	viewer.index = newLineIndex(readerAt)
+	sample := make([]byte, 64*1024)
+	n, _ := readerAt.ReadAt(sample, 0)
+	viewer.bomEncoding, viewer.bom = fileutil.DetectEncoding(sample[:n])
+	viewer.setEncoding(viewer.bomEncoding)
+
+	return &viewer
 }
 
-// getRowAsString fetches the UTF8 characters from a row
-func (v *TextViewer) getRowAsString(rowId int) (utf8 string) {
-	gridRowCells := v.view.Row(rowId).Cells // runes
-	for _, r := range gridRowCells {
-		utf8 += string(r.Rune)
+// Close ends any indexing, searching or following. The reader may be closed afterward.
+func (v *TextViewer) Close() {
+	v.setFollowing(false)
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.closed = true
+	v.generation++
+	if v.owned != nil {
+		_ = v.owned.Close()
+		v.owned = nil
 	}
-	return
 }
 
-// getSearchParameters - a form dialog to get user search criteria
-func (v *TextViewer) getSearchParameters(window fyne.Window, cb func(s searcher)) {
-	var params searcher
-
-	var items = make([]*widget.FormItem, 0)
+func (v *TextViewer) isClosed() bool {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.closed
+}
 
-	entry := widget.NewEntry()
-	entry.PlaceHolder = "3 minimum"
-	find := widget.NewFormItem("Text to find. (minimum 3 chars)", entry)
-	isRegExp := widget.NewCheck("", func(b bool) {
-		params.isRegEx = b
-	})
-	regExp := widget.NewFormItem("Regular Expession?", isRegExp)
-	isCaseSensitive := widget.NewCheck("", func(b bool) {
-		params.caseSensitive = b
+// buildIndex indexes the line starts, updating the view as it goes
+func (v *TextViewer) buildIndex() {
+	defer v.indexing.Unlock()
+	v.mu.Lock()
+	reindexed := v.reindexed
+	v.mu.Unlock()
+	cancel := func() bool {
+		v.mu.Lock()
+		defer v.mu.Unlock()
+		return v.closed || v.reindexed != reindexed
+	}
+	last := time.Now()
+	err := v.index.extend(v.length, cancel, func() {
+		if time.Since(last) > viewerUpdateInterval {
+			last = time.Now()
+			fyne.Do(v.update)
+		}
 	})
-	caseSensitive := widget.NewFormItem("Case Sensitive?", isCaseSensitive)
-	spacer := widget.NewFormItem("", widget.NewLabel("                           "))
-
-	items = append(items, spacer)
-	items = append(items, find)
-	items = append(items, regExp)
-	items = append(items, caseSensitive)
-
-	entry.Validator = func(str string) error {
-		if len(str) < 3 {
-			return errors.New("find string must be > 2 characters")
-		}
-		if params.isRegEx { // Check doesn't support validation
-			re, err := regexp.Compile(str)
-			if err != nil || re == nil {
-				return errors.New("invalid regular expression")
-			}
+	if errors.Is(err, io.EOF) {
+		return // closed, or reindexed
+	}
+	fyne.Do(func() {
+		if err != nil {
+			log.Printf("Error %s indexing: %s\n", err, v.name)
+			v.Err = err
 		}
-		return nil
+		v.indexed = true
+		v.update()
+	})
+}
+
+// update after more lines are indexed
+func (v *TextViewer) update() {
+	if v.pending >= 0 && (v.pending < v.index.count() || v.indexed) {
+		row := v.pending
+		v.pending = -1
+		v.setTop(row)
+	} else {
+		v.setTop(v.top)
 	}
+	v.showStatus()
+}
 
-	dialog.ShowForm("Search Criteria for "+v.name, "search", "cancel", items, func(b bool) {
-		if b {
-			params.find = entry.Text
-			cb(params)
+func (v *TextViewer) showStatus() {
+	status := fmt.Sprintf("lines %d", v.index.count())
+	if !v.indexed {
+		status += " (indexing ...)"
+	}
+	if v.stopFollow != nil {
+		status += ", following"
+	}
+	if v.searchFor != "" {
+		v.mu.Lock()
+		count := len(v.matches)
+		v.mu.Unlock()
+		more := ""
+		if v.searching || count >= maxMatches {
+			more = "+"
+		}
+		if v.current >= 0 {
+			status += fmt.Sprintf(", match %d of %d%s", v.current+1, count, more)
+		} else {
+			status += fmt.Sprintf(", %d%s matches", count, more)
 		}
-	}, window)
+		status += fmt.Sprintf(" for '%s'", v.searchFor)
+	}
+	v.status.SetText(status)
+}
 
-	return
+// lastTop is the top line that shows the end of the file
+func (v *TextViewer) lastTop() int {
+	rows := v.page.rows
+	if rows < 1 {
+		rows = 1
+	}
+	last := v.index.count() - rows
+	if last < 0 {
+		last = 0
+	}
+	return last
 }
 
-func (v *TextViewer) markFinds(s searcher) (matches []match) {
+// setTop scrolls to show a (0 based) line at the top
+func (v *TextViewer) setTop(top int) {
+	last := v.lastTop()
+	if top > last {
+		top = last
+	}
+	if top < 0 {
+		top = 0
+	}
+	v.top = top
+	v.settingBar = true
+	v.bar.Max = float64(last)
+	if v.bar.Max < 1 {
+		v.bar.Max = 1 // a slider needs a range
+	}
+	v.bar.SetValue(v.bar.Max - float64(top))
+	v.settingBar = false
+	v.render()
+}
 
-	find := s.find
-	var re = regexp.MustCompile(find)
-	if !s.caseSensitive {
-		find = strings.ToLower(find)
+func (v *TextViewer) setLeft(left int) {
+	if left < 0 {
+		left = 0
 	}
+	v.left = left
+}
 
-	for r := 0; r < v.nRows; r++ {
-		row := v.getRowAsString(r)
-		m := match{
-			row: -1,
+func (v *TextViewer) typedKey(key *fyne.KeyEvent) {
+	page := v.page.rows - 1
+	if page < 1 {
+		page = 1
+	}
+	switch key.Name {
+	case fyne.KeyUp:
+		v.setTop(v.top - 1)
+	case fyne.KeyDown:
+		v.setTop(v.top + 1)
+	case fyne.KeyPageUp:
+		v.setTop(v.top - page)
+	case fyne.KeyPageDown:
+		v.setTop(v.top + page)
+	case fyne.KeyHome:
+		v.setLeft(0)
+		v.setTop(0)
+	case fyne.KeyEnd:
+		v.setTop(v.lastTop())
+	case fyne.KeyLeft:
+		v.setLeft(v.left - v.tabSize)
+		v.render()
+	case fyne.KeyRight:
+		v.setLeft(v.left + v.tabSize)
+		v.render()
+	case fyne.KeyF3, fyne.KeyReturn, fyne.KeyEnter:
+		v.nextMatch(1)
+	case fyne.KeyF2:
+		v.nextMatch(-1)
+	}
+}
+
+// render reads the visible lines and fills the grid
+func (v *TextViewer) render() {
+	if v.page.rows < 1 {
+		return // not laid out yet
+	}
+	lines := v.lines(v.top, v.page.rows)
+	gutter := 0
+	if v.lineNumbers {
+		gutter = len(strconv.Itoa(v.index.count()))
+	}
+	v.gutter = 0
+	if gutter > 0 {
+		v.gutter = gutter + 1
+	}
+	v.mu.Lock()
+	first := sort.Search(len(v.matches), func(i int) bool {
+		return v.matches[i].row >= v.top
+	})
+	var matches []match
+	for i := first; i < len(v.matches) && v.matches[i].row < v.top+len(lines); i++ {
+		matches = append(matches, v.matches[i])
+	}
+	var current *match
+	if v.current >= 0 && v.current < len(v.matches) {
+		m := v.matches[v.current]
+		current = &m
+	}
+	v.mu.Unlock()
+	start, end := v.anchor, v.selected
+	if end.before(start) {
+		start, end = end, start
+	}
+
+	reverse := &widget.CustomTextGridStyle{
+		TextStyle: fyne.TextStyle{Monospace: true},
+		BGColor:   theme.Color(theme.ColorNameForeground),
+		FGColor:   theme.Color(theme.ColorNameBackground),
+	}
+	focus := &widget.CustomTextGridStyle{
+		TextStyle: fyne.TextStyle{Monospace: true, Bold: true},
+		BGColor:   theme.Color(theme.ColorNamePrimary),
+		FGColor:   theme.Color(theme.ColorNameForegroundOnPrimary),
+	}
+	selection := &widget.CustomTextGridStyle{
+		TextStyle: fyne.TextStyle{Monospace: true},
+		BGColor:   theme.Color(theme.ColorNameSelection),
+	}
+	syntax := make(map[TokenKind]widget.TextGridStyle)
+	for kind, color := range TokenColors {
+		syntax[kind] = &widget.CustomTextGridStyle{
+			TextStyle: fyne.TextStyle{Monospace: true, Bold: kind == HeadingToken},
+			FGColor:   theme.Color(color),
+		}
+	}
+	state := 0 // of the lexer
+	rows := make([]widget.TextGridRow, len(lines))
+	v.shown = make([][]int, len(lines))
+	for i, text := range lines {
+		row := v.top + i
+		cells, at := expandTabs(text, v.tabSize)
+		var tokens []Token
+		if v.highlight && v.lexer != nil {
+			tokens, state = v.lexer.Tokens(text, state)
 		}
-		if s.isRegEx { // (?) prefix for case insensitive
-			cols := re.FindStringIndex(row)
-			if cols != nil {
-				m.row = r
-				m.col1 = cols[0]
-				m.col2 = cols[1]
+		if rule := v.lineColor(text); rule != nil {
+			style := &widget.CustomTextGridStyle{
+				TextStyle: fyne.TextStyle{Monospace: true},
+				FGColor:   theme.Color(rule.Color),
+			}
+			for c := range cells {
+				cells[c].Style = style
 			}
 		} else {
-			if !s.caseSensitive {
-				row = strings.ToLower(row)
+			for _, token := range tokens {
+				style := syntax[token.Kind]
+				for c := at[min(token.Start, len(text))]; c < at[min(token.End, len(text))]; c++ {
+					cells[c].Style = style
+				}
+			}
+		}
+		if row == v.marked {
+			for c := range cells {
+				cells[c].Style = reverse
 			}
-			m.col1 = strings.Index(row, find)
-			if m.col1 > -1 {
-				m.row = r
-				m.col2 = m.col1 + len(find) - 1
+		}
+		if row >= start.row && row <= end.row && start.before(end) {
+			c1, c2 := 0, len(cells)
+			if row == start.row {
+				c1 = at[min(start.col, len(text))]
+			}
+			if row == end.row {
+				c2 = at[min(end.col, len(text))]
 			}
+			for c := c1; c < c2; c++ {
+				cells[c].Style = selection
+			}
+		}
+		for len(matches) > 0 && matches[0].row == row {
+			style := reverse
+			if current != nil && *current == matches[0] {
+				style = focus
+			}
+			for c := at[matches[0].col1]; c < at[matches[0].col2]; c++ {
+				cells[c].Style = style
+			}
+			matches = matches[1:]
+		}
+		v.shown[i] = at
+		if v.left < len(cells) {
+			cells = cells[v.left:]
+		} else {
+			cells = nil
+		}
+		if gutter > 0 {
+			cells = append(lineNumber(row+1, gutter), cells...)
+		}
+		rows[i] = widget.TextGridRow{Cells: cells}
+	}
+	v.page.grid.Rows = rows
+	v.page.grid.Refresh()
+}
+
+// lineNumber cells, right aligned with a separator, as TextGrid shows them
+func lineNumber(n, width int) []widget.TextGridCell {
+	number := strconv.Itoa(n)
+	cells := make([]widget.TextGridCell, 0, width+1)
+	for i := len(number); i < width; i++ {
+		cells = append(cells, widget.TextGridCell{Rune: ' ', Style: widget.TextGridStyleWhitespace})
+	}
+	for _, r := range number {
+		cells = append(cells, widget.TextGridCell{Rune: r})
+	}
+	return append(cells, widget.TextGridCell{Rune: '|', Style: widget.TextGridStyleWhitespace})
+}
+
+// expandTabs converts a line to cells, padding tabs as TextGrid does.
+// at maps each byte offset (and the end) to its cell.
+func expandTabs(text []byte, tabSize int) (cells []widget.TextGridCell, at []int) {
+	cells = make([]widget.TextGridCell, 0, len(text))
+	at = make([]int, len(text)+1)
+	for i := 0; i < len(text); {
+		r, n := utf8.DecodeRune(text[i:])
+		for j := 0; j < n; j++ {
+			at[i+j] = len(cells)
 		}
-		if m.row > -1 {
-			v.highLight(m.row, m.col1, m.col2, true)
-			matches = append(matches, m)
+		cells = append(cells, widget.TextGridCell{Rune: r})
+		if r == '\t' {
+			next := (len(cells) - 1 + tabSize) / tabSize * tabSize
+			for len(cells) < next {
+				cells = append(cells, widget.TextGridCell{Rune: ' '})
+			}
 		}
+		i += n
 	}
+	at[len(text)] = len(cells)
 	return
 }
+
+// ShowRow scrolls a (0 based) row to the top of the view and highlights it.
+// A row not yet indexed is shown when it is.
+func (v *TextViewer) ShowRow(row int) {
+	if row < 0 {
+		return
+	}
+	v.marked = row
+	if row < v.index.count() {
+		v.setTop(row)
+		return
+	}
+	v.pending = row
+}
+
+// positionAt is the text position of a cell of the page
+func (v *TextViewer) positionAt(row, col int) position {
+	if row < 0 || row >= len(v.shown) {
+		return position{row: v.top + row, col: 0}
+	}
+	at := v.shown[row]
+	cell := col - v.gutter + v.left
+	// the last byte starting at or before the cell
+	b := sort.Search(len(at), func(i int) bool {
+		return at[i] > cell
+	}) - 1
+	if b < 0 {
+		b = 0
+	}
+	return position{row: v.top + row, col: b}
+}
+
+// dragged extends the selection, scrolling when above or below the page
+func (v *TextViewer) dragged(row, col int) {
+	if row < 0 {
+		v.setTop(v.top - 1)
+		row = 0
+	} else if row >= v.page.rows {
+		v.setTop(v.top + 1)
+		row = v.page.rows - 1
+	}
+	v.selected = v.positionAt(row, col)
+	v.render()
+}
+
+// copySelection puts the selected text on the clipboard
+func (v *TextViewer) copySelection() {
+	start, end := v.anchor, v.selected
+	if end.before(start) {
+		start, end = end, start
+	}
+	if !start.before(end) {
+		return
+	}
+	lines := v.lines(start.row, end.row-start.row+1)
+	if len(lines) == 0 {
+		return
+	}
+	last := len(lines) - 1
+	if end.row-start.row == last {
+		lines[last] = lines[last][:min(end.col, len(lines[last]))]
+	}
+	lines[0] = lines[0][min(start.col, len(lines[0])):]
+	fyne.CurrentApp().Clipboard().SetContent(string(bytes.Join(lines, []byte("\n"))))
+}
@@ -0,0 +1,295 @@
+package element
+
+/*
+
+  File:    treeViewer.go
+  Author:  Bob Shofner
+
+  MIT License - https://opensource.org/license/mit/
+
+  This permission notice shall be included in all copies
+    or substantial portions of the Software.
+*/
+/*
+  Description: a collapsible tree of JSON or YAML data.
+  Keys are kept in the order of the file.
+*/
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/widget"
+	"gopkg.in/yaml.v3"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// values longer than this are cut short
+const maxTreeValue = 200
+
+// TreeNode is an object, array or value. Values are colored by Kind.
+type TreeNode struct {
+	Key      string
+	Value    string
+	Kind     TokenKind // StringToken, NumberToken, KeywordToken (true, false, null)
+	Children []*TreeNode
+	Branch   bool
+}
+
+type TreeViewer struct {
+	Content *fyne.Container
+	tree    *widget.Tree
+	nodes   map[widget.TreeNodeID]*TreeNode
+}
+
+func NewTreeViewer(name string, root *TreeNode, buttons []*widget.Button) *TreeViewer {
+	viewer := &TreeViewer{nodes: make(map[widget.TreeNodeID]*TreeNode)}
+	if !root.Branch { // the tree shows the children of the root
+		root = &TreeNode{Children: []*TreeNode{root}, Branch: true}
+	}
+	viewer.nodes[""] = root
+
+	title := widget.NewLabel(name)
+	title.Importance = widget.HighImportance
+	title.Alignment = fyne.TextAlignTrailing
+
+	viewer.tree = widget.NewTree(viewer.childIDs, func(id widget.TreeNodeID) bool {
+		node := viewer.nodes[id]
+		return node != nil && node.Branch
+	}, func(bool) fyne.CanvasObject {
+		return widget.NewRichText()
+	}, viewer.updateNode)
+
+	expand := widget.NewButton("EXPAND ALL", func() {
+		viewer.tree.OpenAllBranches()
+	})
+	collapse := widget.NewButton("COLLAPSE ALL", func() {
+		viewer.tree.CloseAllBranches()
+	})
+	buttonBar := container.NewHBox(expand, collapse)
+
+	// add in any user buttons
+	if len(buttons) > 0 {
+		buttonBar.Objects = append(buttonBar.Objects, layout.NewSpacer())
+		for _, button := range buttons {
+			buttonBar.Objects = append(buttonBar.Objects, button)
+		}
+	}
+	viewer.Content = container.NewBorder(title, buttonBar, nil, nil, viewer.tree)
+	viewer.tree.OpenBranch("")
+	return viewer
+}
+
+// childIDs are the paths of the children, as "0/3/1"
+func (v *TreeViewer) childIDs(id widget.TreeNodeID) []widget.TreeNodeID {
+	node := v.nodes[id]
+	if node == nil {
+		return nil
+	}
+	ids := make([]widget.TreeNodeID, len(node.Children))
+	for i, child := range node.Children {
+		ids[i] = strconv.Itoa(i)
+		if id != "" {
+			ids[i] = id + "/" + ids[i]
+		}
+		v.nodes[ids[i]] = child
+	}
+	return ids
+}
+
+func (v *TreeViewer) updateNode(id widget.TreeNodeID, branch bool, object fyne.CanvasObject) {
+	node := v.nodes[id]
+	text := object.(*widget.RichText)
+	if node == nil {
+		text.Segments = nil
+		text.Refresh()
+		return
+	}
+	key := &widget.TextSegment{Text: node.Key, Style: widget.RichTextStyleStrong}
+	key.Style.Inline = true
+	value := &widget.TextSegment{Text: node.Value, Style: widget.RichTextStyleInline}
+	if branch {
+		value.Style.ColorName = TokenColors[CommentToken]
+	} else {
+		value.Style.ColorName = TokenColors[node.Kind]
+	}
+	if node.Key != "" {
+		key.Text += ": "
+	}
+	text.Segments = []widget.RichTextSegment{key, value}
+	text.Refresh()
+}
+
+// branch summary, as {3} or [5]
+func (n *TreeNode) summarize(open, close string) {
+	n.Branch = true
+	n.Value = fmt.Sprintf("%s%d%s", open, len(n.Children), close)
+}
+
+func (n *TreeNode) setValue(value string, kind TokenKind) {
+	n.Kind = kind
+	if len([]rune(value)) > maxTreeValue {
+		value = string([]rune(value)[:maxTreeValue]) + "..."
+	}
+	n.Value = strings.ReplaceAll(value, "\n", "\\n")
+}
+
+// ParseJSONTree reads JSON, or a series of JSON values (as JSON lines)
+func ParseJSONTree(r io.Reader) (*TreeNode, error) {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	values := make([]*TreeNode, 0)
+	for {
+		node, err := jsonNode(dec, "")
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, node)
+	}
+	switch len(values) {
+	case 0:
+		return nil, errors.New("no JSON values")
+	case 1:
+		return values[0], nil
+	}
+	root := &TreeNode{Children: values}
+	for i, value := range values {
+		value.Key = fmt.Sprintf("[%d]", i)
+	}
+	root.summarize("[", "]")
+	return root, nil
+}
+
+func jsonNode(dec *json.Decoder, key string) (*TreeNode, error) {
+	token, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	node := &TreeNode{Key: key}
+	switch t := token.(type) {
+	case json.Delim:
+		switch t {
+		case '{':
+			for dec.More() {
+				name, err := dec.Token()
+				if err != nil {
+					return nil, err
+				}
+				child, err := jsonNode(dec, fmt.Sprint(name))
+				if err != nil {
+					return nil, unexpected(err)
+				}
+				node.Children = append(node.Children, child)
+			}
+			node.summarize("{", "}")
+		case '[':
+			for i := 0; dec.More(); i++ {
+				child, err := jsonNode(dec, fmt.Sprintf("[%d]", i))
+				if err != nil {
+					return nil, unexpected(err)
+				}
+				node.Children = append(node.Children, child)
+			}
+			node.summarize("[", "]")
+		default:
+			return nil, fmt.Errorf("unexpected %s", t)
+		}
+		if _, err = dec.Token(); err != nil { // the closing delimiter
+			return nil, unexpected(err)
+		}
+	case string:
+		node.setValue(strconv.Quote(t), StringToken)
+	case json.Number:
+		node.setValue(t.String(), NumberToken)
+	case bool:
+		node.setValue(strconv.FormatBool(t), KeywordToken)
+	case nil:
+		node.setValue("null", KeywordToken)
+	}
+	return node, nil
+}
+
+// unexpected is an error within a value, where the end of the input is an error
+func unexpected(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// ParseYAMLTree reads YAML, with a branch for each document if more than one
+func ParseYAMLTree(r io.Reader) (*TreeNode, error) {
+	dec := yaml.NewDecoder(r)
+	docs := make([]*TreeNode, 0)
+	for {
+		var doc yaml.Node
+		err := dec.Decode(&doc)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, yamlNode(&doc, "", 0))
+	}
+	switch len(docs) {
+	case 0:
+		return nil, errors.New("no YAML documents")
+	case 1:
+		return docs[0], nil
+	}
+	root := &TreeNode{Children: docs}
+	for i, doc := range docs {
+		doc.Key = fmt.Sprintf("--- %d", i+1)
+	}
+	root.summarize("[", "]")
+	return root, nil
+}
+
+// yamlNode converts a node, aliases are shown as *anchor rather than copied
+func yamlNode(n *yaml.Node, key string, depth int) *TreeNode {
+	node := &TreeNode{Key: key}
+	if depth > 100 {
+		node.setValue("...", CommentToken)
+		return node
+	}
+	switch n.Kind {
+	case yaml.DocumentNode:
+		if len(n.Content) > 0 {
+			return yamlNode(n.Content[0], key, depth+1)
+		}
+		node.setValue("", PlainToken)
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			node.Children = append(node.Children, yamlNode(n.Content[i+1], n.Content[i].Value, depth+1))
+		}
+		node.summarize("{", "}")
+	case yaml.SequenceNode:
+		for i, item := range n.Content {
+			node.Children = append(node.Children, yamlNode(item, fmt.Sprintf("[%d]", i), depth+1))
+		}
+		node.summarize("[", "]")
+	case yaml.AliasNode:
+		node.setValue("*"+n.Value, TypeToken)
+	default:
+		switch n.ShortTag() {
+		case "!!int", "!!float":
+			node.setValue(n.Value, NumberToken)
+		case "!!null":
+			node.setValue("null", KeywordToken)
+		case "!!bool":
+			node.setValue(n.Value, KeywordToken)
+		default:
+			node.setValue(n.Value, StringToken)
+		}
+	}
+	return node
+}
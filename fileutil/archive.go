@@ -0,0 +1,160 @@
+package fileutil
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+/*
+
+  File:    archive.go
+  Author:  Bob Shofner
+
+  MIT License - https://opensource.org/license/mit/
+
+  This permission notice shall be included in all copies
+    or substantial portions of the Software.
+
+*/
+/*
+  Description: the table of contents of a zip, tar or gzip (tar.gz, or a single
+  compressed file) archive, without extracting it. The kind is found from
+  the content, not the extension.
+*/
+
+// ArchiveEntry is a file, directory or link in an archive.
+// Compressed is -1 if not known (the entries of a compressed tar).
+type ArchiveEntry struct {
+	Name       string
+	Size       int64
+	Compressed int64
+	Modified   time.Time
+	Mode       fs.FileMode
+	Link       string // target of a link
+}
+
+// ErrNotArchive is a file that isn't a zip, tar or gzip
+var ErrNotArchive = errors.New("not a zip, tar or gzip archive")
+
+// ListArchive is the entries of an archive, at most limit if not 0.
+// cancel is checked for each entry, returning true ends the listing with io.EOF.
+func ListArchive(path string, limit int, cancel func() bool) ([]ArchiveEntry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+	head := make([]byte, 512)
+	n, _ := io.ReadFull(file, head)
+	head = head[:n]
+	if _, err = file.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	switch {
+	case bytes.HasPrefix(head, []byte("PK\x03\x04")) || bytes.HasPrefix(head, []byte("PK\x05\x06")):
+		return listZip(path, limit, cancel)
+	case bytes.HasPrefix(head, []byte{0x1f, 0x8b}):
+		return listGzip(file, limit, cancel)
+	case isTar(head):
+		fakeTar := &Tar{tarFile: path}
+		fakeTar.reader = tar.NewReader(file)
+		return fakeTar.List(limit, cancel)
+	}
+	return nil, ErrNotArchive
+}
+
+// isTar checks the header checksum of the first block
+func isTar(head []byte) bool {
+	if len(head) < 512 {
+		return false
+	}
+	if string(head[257:262]) == "ustar" {
+		return true
+	}
+	field := strings.Trim(string(head[148:156]), " \x00")
+	if field == "" {
+		return false
+	}
+	var sum int64
+	for i, b := range head {
+		if i >= 148 && i < 156 {
+			b = ' '
+		}
+		sum += int64(b)
+	}
+	var want int64
+	for _, c := range field {
+		if c < '0' || c > '7' {
+			return false
+		}
+		want = want*8 + int64(c-'0')
+	}
+	return sum == want
+}
+
+func listZip(path string, limit int, cancel func() bool) ([]ArchiveEntry, error) {
+	z, err := NewUnZipper(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = z.reader.Close()
+	}()
+	return z.List(limit, cancel)
+}
+
+// listGzip lists a compressed tar, or the single file compressed
+func listGzip(file *os.File, limit int, cancel func() bool) ([]ArchiveEntry, error) {
+	buffered := bufio.NewReader(file)
+	reader, err := gzip.NewReader(buffered)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = reader.Close()
+	}()
+	inner := bufio.NewReaderSize(reader, 512)
+	head, _ := inner.Peek(512)
+	if isTar(head) {
+		fakeTar := &Tar{}
+		fakeTar.reader = tar.NewReader(inner)
+		entries, err := fakeTar.List(limit, cancel)
+		for i := range entries {
+			entries[i].Compressed = -1
+		}
+		return entries, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	name := reader.Header.Name
+	if name == "" {
+		name = strings.TrimSuffix(filepath.Base(file.Name()), filepath.Ext(file.Name()))
+	}
+	entry := ArchiveEntry{
+		Name:       name,
+		Size:       -1,
+		Compressed: info.Size(),
+		Modified:   reader.Header.ModTime,
+		Mode:       0644,
+	}
+	// the size (modulo 4G) is the last 4 bytes
+	trailer := make([]byte, 4)
+	if _, err = file.ReadAt(trailer, info.Size()-4); err == nil {
+		entry.Size = int64(binary.LittleEndian.Uint32(trailer))
+	}
+	return []ArchiveEntry{entry}, nil
+}
@@ -0,0 +1,119 @@
+package fileutil
+
+import (
+	"bytes"
+	"io"
+	"os"
+)
+
+/*
+
+  File:    compare.go
+  Author:  Bob Shofner
+
+  MIT License - https://opensource.org/license/mit/
+
+  This permission notice shall be included in all copies
+    or substantial portions of the Software.
+
+*/
+/*
+  Description: compare two files byte by byte
+*/
+
+// only this many runs of differences are kept
+var maxByteDiffs = 100000
+
+// ByteDiff is a run of differing bytes, at the same offset in both files
+type ByteDiff struct {
+	Offset int64
+	Length int64
+}
+
+type ByteCompare struct {
+	Sizes     [2]int64
+	Differing int64      // bytes that differ, up to the end of the shorter file
+	Diffs     []ByteDiff // the first maxByteDiffs runs
+	More      bool       // there were more runs than kept
+}
+
+// Identical reports the same sizes and bytes
+func (c *ByteCompare) Identical() bool {
+	return c.Sizes[0] == c.Sizes[1] && c.Differing == 0
+}
+
+// First is the offset of the first difference, the end of the shorter file
+// if that is all that differs, or -1 for none.
+func (c *ByteCompare) First() int64 {
+	if len(c.Diffs) > 0 {
+		return c.Diffs[0].Offset
+	}
+	if c.Sizes[0] != c.Sizes[1] {
+		return min(c.Sizes[0], c.Sizes[1])
+	}
+	return -1
+}
+
+// CompareBytes compares two files. cancel is checked regularly,
+// returning true ends the compare with io.EOF.
+func CompareBytes(a, b string, cancel func() bool) (*ByteCompare, error) {
+	var files [2]*os.File
+	result := &ByteCompare{Diffs: make([]ByteDiff, 0)}
+	for i, path := range []string{a, b} {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer func() {
+			_ = f.Close()
+		}()
+		info, err := f.Stat()
+		if err != nil {
+			return nil, err
+		}
+		files[i] = f
+		result.Sizes[i] = info.Size()
+	}
+
+	const chunk = 1024 * 1024
+	bufs := [2][]byte{make([]byte, chunk), make([]byte, chunk)}
+	var offset int64
+	for {
+		if cancel() {
+			return nil, io.EOF
+		}
+		var n [2]int
+		for i, f := range files {
+			var err error
+			n[i], err = io.ReadFull(f, bufs[i])
+			if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+				return nil, err
+			}
+		}
+		common := min(n[0], n[1])
+		x, y := bufs[0][:common], bufs[1][:common]
+		if !bytes.Equal(x, y) {
+			for i := 0; i < common; i++ {
+				if x[i] == y[i] {
+					continue
+				}
+				result.Differing++
+				at := offset + int64(i)
+				last := len(result.Diffs) - 1
+				switch {
+				case last >= 0 && result.Diffs[last].Offset+result.Diffs[last].Length == at:
+					result.Diffs[last].Length++
+				case len(result.Diffs) < maxByteDiffs:
+					result.Diffs = append(result.Diffs, ByteDiff{Offset: at, Length: 1})
+				default:
+					result.More = true
+				}
+			}
+		}
+		offset += int64(common)
+		if n[0] < chunk || n[1] < chunk {
+			break
+		}
+	}
+	return result, nil
+}
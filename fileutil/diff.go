@@ -0,0 +1,165 @@
+package fileutil
+
+import (
+	"strings"
+)
+
+/*
+
+  File:    diff.go
+  Author:  Bob Shofner
+
+  MIT License - https://opensource.org/license/mit/
+
+  This permission notice shall be included in all copies
+    or substantial portions of the Software.
+
+*/
+/*
+  Description: compare two texts by lines (Myers' O(ND) difference algorithm).
+  Lines are numbered for comparison, so each is only compared as a whole once.
+*/
+
+// beyond this many changed lines the rest is shown as one change
+var maxDiffEdits = 4000
+
+// DiffHunk is a run of lines, the same in both texts or changed.
+// Lines A1 to A2 (exclusive) of the first text correspond to B1 to B2 of the second.
+type DiffHunk struct {
+	Equal  bool
+	A1, A2 int
+	B1, B2 int
+}
+
+// DiffLines compares lines. Ignoring white space, lines differing only
+// in the amount of spaces and tabs (or at the ends) are the same.
+func DiffLines(a, b []string, ignoreSpace bool) []DiffHunk {
+	ids := make(map[string]int)
+	number := func(lines []string) []int {
+		numbers := make([]int, len(lines))
+		for i, line := range lines {
+			if ignoreSpace {
+				line = strings.Join(strings.Fields(line), " ")
+			}
+			id, ok := ids[line]
+			if !ok {
+				id = len(ids)
+				ids[line] = id
+			}
+			numbers[i] = id
+		}
+		return numbers
+	}
+	x, y := number(a), number(b)
+
+	// the same at the start and end
+	start := 0
+	for start < len(x) && start < len(y) && x[start] == y[start] {
+		start++
+	}
+	endX, endY := len(x), len(y)
+	for endX > start && endY > start && x[endX-1] == y[endY-1] {
+		endX--
+		endY--
+	}
+	matches, ok := diffMatches(x[start:endX], y[start:endY])
+	if !ok {
+		matches = nil
+	}
+
+	hunks := make([]DiffHunk, 0)
+	add := func(equal bool, a1, a2, b1, b2 int) {
+		if a1 == a2 && b1 == b2 {
+			return
+		}
+		if n := len(hunks) - 1; n >= 0 && hunks[n].Equal == equal {
+			hunks[n].A2, hunks[n].B2 = a2, b2
+			return
+		}
+		hunks = append(hunks, DiffHunk{Equal: equal, A1: a1, A2: a2, B1: b1, B2: b2})
+	}
+	add(true, 0, start, 0, start)
+	i, j := start, start
+	for _, m := range matches {
+		mi, mj := m[0]+start, m[1]+start
+		add(false, i, mi, j, mj)
+		add(true, mi, mi+1, mj, mj+1)
+		i, j = mi+1, mj+1
+	}
+	add(false, i, endX, j, endY)
+	add(true, endX, len(x), endY, len(y))
+	return hunks
+}
+
+// diffMatches finds the longest common subsequence of a and b, as pairs of
+// matching indexes. It gives up (false) past maxDiffEdits.
+func diffMatches(a, b []int) ([][2]int, bool) {
+	n, m := len(a), len(b)
+	if n == 0 || m == 0 {
+		return nil, true
+	}
+	max := n + m
+	offset := max + 1
+	v := make([]int, 2*max+3)
+	trace := make([][]int32, 0) // v of each d, for k = -d to d
+	for d := 0; d <= max; d++ {
+		if d > maxDiffEdits {
+			return nil, false
+		}
+		done := false
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1] // down, an insert
+			} else {
+				x = v[offset+k-1] + 1 // right, a delete
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				done = true
+			}
+		}
+		saved := make([]int32, 2*d+1)
+		for k := -d; k <= d; k++ {
+			saved[k+d] = int32(v[offset+k])
+		}
+		trace = append(trace, saved)
+		if done {
+			break
+		}
+	}
+
+	// back from the end, collecting the diagonals
+	matches := make([][2]int, 0)
+	x, y := n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		k := x - y
+		prevX, prevY := 0, 0
+		if d > 0 {
+			prev := func(k int) int {
+				return int(trace[d-1][k+d-1])
+			}
+			prevK := k - 1
+			if k == -d || (k != d && prev(k-1) < prev(k+1)) {
+				prevK = k + 1
+			}
+			prevX = prev(prevK)
+			prevY = prevX - prevK
+		}
+		for x > prevX && y > prevY {
+			x--
+			y--
+			matches = append(matches, [2]int{x, y})
+		}
+		x, y = prevX, prevY
+	}
+	for i, j := 0, len(matches)-1; i < j; i, j = i+1, j-1 {
+		matches[i], matches[j] = matches[j], matches[i]
+	}
+	return matches, true
+}
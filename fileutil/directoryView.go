@@ -3,6 +3,8 @@ package fileutil
 import (
 	"errors"
 	"fmt"
+	"io/fs"
+	"os"
 	"path/filepath"
 	"sort"
 	"strings"
@@ -42,20 +44,55 @@ func NewDirectoryView(path string, sel FileSelectFilter) (*DirectoryEntry, error
 	if err != nil {
 		return de, err
 	}
+	sortEntries(de.files, sel)
+	return de, nil
+}
+
+// NewVirtualDirectoryView creates a DirectoryEntry of arbitrary paths below root.
+// Entries display as the path relative to root, so joining root and
+// the DisplayName still gives the real path.
+func NewVirtualDirectoryView(root string, paths []string, sel FileSelectFilter) *DirectoryEntry {
+	de := NewDirectoryEntry(root)
+	for _, path := range paths {
+		info, err := os.Lstat(path)
+		if err != nil {
+			continue // gone since it was found
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil || !InsideDir(path, root) {
+			continue
+		}
+		entry := virtualEntry{DirEntry: fs.FileInfoToDirEntry(info), name: rel}
+		de.files = append(de.files, FileEntry{parent: root, entry: entry})
+	}
+	sortEntries(de.files, sel)
+	return de
+}
+
+// virtualEntry renames a DirEntry to its path relative to the listing
+type virtualEntry struct {
+	fs.DirEntry
+	name string
+}
+
+func (v virtualEntry) Name() string {
+	return v.name
+}
+
+func sortEntries(files []FileEntry, sel FileSelectFilter) {
 	if sel.Date {
 		if sel.Descending {
-			sortDateTimeDescending(de.files)
+			sortDateTimeDescending(files)
 		} else {
-			sortDateTimeAscending(de.files)
+			sortDateTimeAscending(files)
 		}
 	} else {
 		if sel.Descending {
-			sortNameDescending(de.files)
+			sortNameDescending(files)
 		} else {
-			sortNameAscending(de.files)
+			sortNameAscending(files)
 		}
 	}
-	return de, nil
 }
 
 func sortNameAscending(slice []FileEntry) {
@@ -0,0 +1,198 @@
+package fileutil
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+/*
+
+  File:    duplicates.go
+  Author:  Bob Shofner
+
+  MIT License - https://opensource.org/license/mit/
+
+  This permission notice shall be included in all copies
+    or substantial portions of the Software.
+
+*/
+/*
+  Description: find files with identical contents.
+  Files are grouped by size, then a hash of the first block, then a hash of everything.
+*/
+
+var partialHashSize int64 = 64 * 1024
+
+type DuplicateFile struct {
+	Path string
+	Info fs.FileInfo
+}
+
+type DuplicateGroup struct {
+	Size  int64
+	Hash  string
+	Files []DuplicateFile // oldest first
+}
+
+// FindDuplicates scans the roots for (non-empty) files with the same contents.
+// cancel is checked regularly, returning true ends the scan with io.EOF.
+func FindDuplicates(roots []string, cancel func() bool) ([]DuplicateGroup, error) {
+	bySize := make(map[int64][]DuplicateFile)
+	seen := make(map[string]bool)
+	for _, root := range roots {
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if cancel() {
+				return io.EOF
+			}
+			if err != nil || !d.Type().IsRegular() || seen[path] {
+				return nil
+			}
+			seen[path] = true
+			info, err := d.Info()
+			if err != nil || info.Size() == 0 {
+				return nil
+			}
+			bySize[info.Size()] = append(bySize[info.Size()], DuplicateFile{Path: path, Info: info})
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	groups := make([]DuplicateGroup, 0)
+	for size, files := range bySize {
+		if len(files) < 2 {
+			continue
+		}
+		files = withoutLinks(files)
+		for hash, partial := range groupByHash(files, partialHashSize, cancel) {
+			if cancel() {
+				return nil, io.EOF
+			}
+			// small files are completely hashed by the partial hash
+			full := map[string][]DuplicateFile{hash: partial}
+			if size > partialHashSize {
+				full = groupByHash(partial, 0, cancel)
+			}
+			for hash, same := range full {
+				sort.SliceStable(same, func(i, j int) bool {
+					return same[i].Info.ModTime().Before(same[j].Info.ModTime())
+				})
+				groups = append(groups, DuplicateGroup{Size: size, Hash: hash, Files: same})
+			}
+		}
+	}
+	if cancel() {
+		return nil, io.EOF
+	}
+	// largest savings first
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i].Size*int64(len(groups[i].Files)-1) > groups[j].Size*int64(len(groups[j].Files)-1)
+	})
+	return groups, nil
+}
+
+// withoutLinks keeps only one path to the same (hard linked) file
+func withoutLinks(files []DuplicateFile) []DuplicateFile {
+	unique := make([]DuplicateFile, 0, len(files))
+	for _, f := range files {
+		linked := false
+		for _, u := range unique {
+			if os.SameFile(f.Info, u.Info) {
+				linked = true
+				break
+			}
+		}
+		if !linked {
+			unique = append(unique, f)
+		}
+	}
+	return unique
+}
+
+// groupByHash keeps groups (of 2 or more) with the same hash of the first n bytes (0 is all)
+func groupByHash(files []DuplicateFile, n int64, cancel func() bool) map[string][]DuplicateFile {
+	byHash := make(map[string][]DuplicateFile)
+	if len(files) < 2 {
+		return byHash
+	}
+	for _, f := range files {
+		if cancel() {
+			break
+		}
+		hash := hashOf(f.Path, n)
+		if hash != "" {
+			byHash[hash] = append(byHash[hash], f)
+		}
+	}
+	for hash, same := range byHash {
+		if len(same) < 2 {
+			delete(byHash, hash)
+		}
+	}
+	return byHash
+}
+
+// hashOf is the sha256 of the first n bytes of a file (0 is all), "" on error
+func hashOf(path string, n int64) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	h := sha256.New()
+	var r io.Reader = f
+	if n > 0 {
+		r = io.LimitReader(f, n)
+	}
+	if _, err = io.Copy(h, r); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// ErrChanged is a file changed since it was found a duplicate
+var ErrChanged = errors.New("changed since the scan")
+
+// Unchanged is an error if a file of the group is not as scanned,
+// its size, modified time or (whole) hash differ.
+func (g DuplicateGroup) Unchanged(file DuplicateFile) error {
+	info, err := os.Lstat(file.Path)
+	if err != nil {
+		return err
+	}
+	if !info.Mode().IsRegular() || info.Size() != g.Size || !info.ModTime().Equal(file.Info.ModTime()) ||
+		hashOf(file.Path, 0) != g.Hash {
+		return fmt.Errorf("%s %w", file.Path, ErrChanged)
+	}
+	return nil
+}
+
+// ReplaceWithLink replaces duplicate with a hard link to keep,
+// if both are as scanned
+func ReplaceWithLink(group DuplicateGroup, keep, duplicate DuplicateFile) error {
+	for _, file := range []DuplicateFile{keep, duplicate} {
+		if err := group.Unchanged(file); err != nil {
+			return err
+		}
+	}
+	tmp := duplicate.Path + ".fman-link"
+	_ = os.Remove(tmp)
+	if err := os.Link(keep.Path, tmp); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, duplicate.Path); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	return nil
+}
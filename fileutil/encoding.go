@@ -0,0 +1,272 @@
+package fileutil
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+/*
+
+  File:    encoding.go
+  Author:  Bob Shofner
+
+  MIT License - https://opensource.org/license/mit/
+
+  This permission notice shall be included in all copies
+    or substantial portions of the Software.
+
+*/
+/*
+  Description: detect and convert text file character encodings and line endings.
+*/
+
+const (
+	UTF8        = "UTF-8"
+	UTF16LE     = "UTF-16LE"
+	UTF16BE     = "UTF-16BE"
+	Windows1252 = "Windows-1252"
+	Latin1      = "ISO-8859-1"
+	ShiftJIS    = "Shift-JIS"
+)
+
+var Encodings = []string{UTF8, UTF16LE, UTF16BE, Windows1252, Latin1, ShiftJIS}
+
+const (
+	LF   = "LF"
+	CRLF = "CRLF"
+	CR   = "CR"
+)
+
+var LineEndings = []string{LF, CRLF, CR}
+
+var lineEnding = map[string]string{LF: "\n", CRLF: "\r\n", CR: "\r"}
+
+// TextEncoding is the encoding of a name in Encodings, nil if unknown
+func TextEncoding(name string) encoding.Encoding {
+	switch name {
+	case UTF8:
+		return unicode.UTF8
+	case UTF16LE:
+		return unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM)
+	case UTF16BE:
+		return unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM)
+	case Windows1252:
+		return charmap.Windows1252
+	case Latin1:
+		return charmap.ISO8859_1
+	case ShiftJIS:
+		return japanese.ShiftJIS
+	}
+	return nil
+}
+
+// ByteOrderMark of a Unicode encoding, nil for others
+func ByteOrderMark(name string) []byte {
+	switch name {
+	case UTF8:
+		return []byte{0xef, 0xbb, 0xbf}
+	case UTF16LE:
+		return []byte{0xff, 0xfe}
+	case UTF16BE:
+		return []byte{0xfe, 0xff}
+	}
+	return nil
+}
+
+// DetectEncoding guesses the encoding of a sample from the start of a file,
+// and the length of any byte order mark.
+func DetectEncoding(sample []byte) (name string, bom int) {
+	return detectEncoding(sample, true)
+}
+
+// detectEncoding is of a sample that may be cut, or a whole file
+func detectEncoding(sample []byte, cut bool) (name string, bom int) {
+	for _, name := range []string{UTF8, UTF16LE, UTF16BE} {
+		if mark := ByteOrderMark(name); bytes.HasPrefix(sample, mark) {
+			return name, len(mark)
+		}
+	}
+	// UTF-16 (of mostly ASCII) without a BOM has NULs in every other byte
+	var even, odd int
+	for i := 0; i+1 < len(sample); i += 2 {
+		if sample[i] == 0 {
+			even++
+		}
+		if sample[i+1] == 0 {
+			odd++
+		}
+	}
+	pairs := len(sample) / 2
+	if pairs > 1 && odd > pairs/3 && even < pairs/20 {
+		return UTF16LE, 0
+	}
+	if pairs > 1 && even > pairs/3 && odd < pairs/20 {
+		return UTF16BE, 0
+	}
+	// the sample may end part way through a character
+	valid := sample
+	for i := 0; cut && i < utf8.UTFMax-1 && len(valid) > 0 && !utf8.Valid(valid); i++ {
+		valid = valid[:len(valid)-1]
+	}
+	if utf8.Valid(valid) {
+		return UTF8, 0
+	}
+	if looksShiftJIS(sample) {
+		return ShiftJIS, 0
+	}
+	// 0x80 - 0x9f are control characters in Latin-1, but printable in Windows-1252
+	for _, b := range sample {
+		if b >= 0x80 && b <= 0x9f {
+			return Windows1252, 0
+		}
+	}
+	return Latin1, 0
+}
+
+// LooksBinary guesses a sample from the start of a file is not text:
+// it has NUL bytes, or NUL characters if it looks like UTF-16.
+func LooksBinary(sample []byte) bool {
+	if name, _ := DetectEncoding(sample); name == UTF16LE || name == UTF16BE {
+		for i := 0; i+1 < len(sample); i += 2 {
+			if sample[i] == 0 && sample[i+1] == 0 {
+				return true
+			}
+		}
+		return false
+	}
+	return bytes.IndexByte(sample, 0) >= 0
+}
+
+// looksShiftJIS checks for valid double byte characters.
+// Japanese text nearly always has some with a lead byte of 0x81 - 0x9f (kana and punctuation).
+func looksShiftJIS(sample []byte) bool {
+	kana := false
+	for i := 0; i < len(sample); i++ {
+		b := sample[i]
+		switch {
+		case b < 0x80, b >= 0xa1 && b <= 0xdf: // ASCII, half width katakana
+		case b >= 0x81 && b <= 0x9f, b >= 0xe0 && b <= 0xfc:
+			if i+1 == len(sample) {
+				return kana // cut off
+			}
+			trail := sample[i+1]
+			if trail < 0x40 || trail == 0x7f || trail > 0xfc {
+				return false
+			}
+			if b <= 0x9f {
+				kana = true
+			}
+			i++
+		default:
+			return false
+		}
+	}
+	return kana
+}
+
+// DetectLineEnding is the most common line ending in a (UTF-8 or single byte) sample
+func DetectLineEnding(sample []byte) string {
+	crlf := bytes.Count(sample, []byte("\r\n"))
+	lf := bytes.Count(sample, []byte("\n")) - crlf
+	cr := bytes.Count(sample, []byte("\r")) - crlf
+	switch {
+	case crlf > lf && crlf >= cr:
+		return CRLF
+	case cr > lf:
+		return CR
+	}
+	return LF
+}
+
+// ConvertFile rewrites a text file in another encoding and / or line ending.
+// An empty from is detected, an empty ending is unchanged. Characters that
+// can't be encoded are replaced.
+func ConvertFile(path, from, to, ending string, bom bool) error {
+	in, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = in.Close()
+	}()
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+	reader := bufio.NewReaderSize(in, 64*1024)
+	sample, _ := reader.Peek(64 * 1024)
+	detected, skip := DetectEncoding(sample)
+	if from == "" {
+		from = detected
+	} else if detected != from {
+		skip = 0 // only skip a BOM of the same encoding
+	}
+	source, target := TextEncoding(from), TextEncoding(to)
+	if source == nil || target == nil {
+		return errors.New("unknown encoding " + from + " to " + to)
+	}
+	if _, err = reader.Discard(skip); err != nil {
+		return err
+	}
+
+	out, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	tmp := out.Name()
+	fail := func(err error) error {
+		_ = out.Close()
+		_ = os.Remove(tmp)
+		return err
+	}
+	if mark := ByteOrderMark(to); bom && mark != nil {
+		if _, err = out.Write(mark); err != nil {
+			return fail(err)
+		}
+	}
+	writer := transform.NewWriter(out, encoding.ReplaceUnsupported(target.NewEncoder()))
+	lines := bufio.NewReaderSize(transform.NewReader(reader, source.NewDecoder()), 64*1024)
+	for {
+		line, err := lines.ReadString('\n')
+		if nl, ok := lineEnding[ending]; ok && line != "" {
+			line = strings.ReplaceAll(line, "\r\n", "\n")
+			line = strings.ReplaceAll(line, "\r", "\n")
+			if nl != "\n" {
+				line = strings.ReplaceAll(line, "\n", nl)
+			}
+		}
+		if _, e := io.WriteString(writer, line); e != nil {
+			return fail(e)
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fail(err)
+		}
+	}
+	if err = writer.Close(); err != nil {
+		return fail(err)
+	}
+	if err = out.Close(); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	_ = os.Chmod(tmp, info.Mode().Perm())
+	if err = os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	return nil
+}
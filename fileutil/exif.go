@@ -0,0 +1,334 @@
+package fileutil
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+	"strings"
+)
+
+/*
+
+  File:    exif.go
+  Author:  Bob Shofner
+
+  MIT License - https://opensource.org/license/mit/
+
+  This permission notice shall be included in all copies
+    or substantial portions of the Software.
+
+*/
+/*
+  Description: the EXIF data of JPEG and TIFF images. The data is a TIFF
+  structure, of IFDs (directories) of tagged entries.
+*/
+
+const (
+	exifOrientationTag = 0x0112
+
+	// entry types
+	exifByte      = 1
+	exifASCII     = 2
+	exifShort     = 3
+	exifLong      = 4
+	exifRational  = 5
+	exifUndefined = 7
+	exifSLong     = 9
+	exifSRational = 10
+)
+
+// bytes of each entry type
+var exifSizes = map[uint16]int{
+	exifByte: 1, exifASCII: 1, exifShort: 2, exifLong: 4, exifRational: 8,
+	exifUndefined: 1, exifSLong: 4, exifSRational: 8,
+}
+
+var exifHeader = []byte("Exif\x00\x00")
+
+// ErrNoOrientation is returned when the EXIF data has no orientation to change
+var ErrNoOrientation = errors.New("the EXIF data has no orientation entry")
+
+// exifData is the TIFF structure, at offset in the file
+type exifData struct {
+	order  binary.ByteOrder
+	tiff   []byte
+	offset int64
+}
+
+// exifEntry is a 12 byte IFD entry, at (TIFF) offset at
+type exifEntry struct {
+	tag, kind uint16
+	count     uint32
+	at        int
+}
+
+// readExif finds the EXIF data of a JPEG or TIFF file, nil if none
+func readExif(path string) (*exifData, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+	head := make([]byte, 4)
+	if _, err = io.ReadFull(file, head); err != nil {
+		return nil, nil
+	}
+	switch {
+	case head[0] == 0xff && head[1] == 0xd8:
+		return jpegExif(file)
+	case string(head) == "II*\x00" || string(head) == "MM\x00*":
+		info, err := file.Stat()
+		if err != nil {
+			return nil, err
+		}
+		tiff := make([]byte, min(info.Size(), 1024*1024)) // the IFDs are (almost always) first
+		n, _ := file.ReadAt(tiff, 0)
+		return newExifData(tiff[:n], 0), nil
+	}
+	return nil, nil
+}
+
+// jpegExif reads the segments of a JPEG, up to the image data, for the Exif APP1
+func jpegExif(file io.ReadSeeker) (*exifData, error) {
+	offset, err := file.Seek(2, io.SeekStart)
+	if err != nil {
+		return nil, err
+	}
+	marker := make([]byte, 4)
+	for {
+		if _, err = io.ReadFull(file, marker); err != nil || marker[0] != 0xff {
+			return nil, nil
+		}
+		if marker[1] == 0xda || marker[1] == 0xd9 { // start of scan, end of image
+			return nil, nil
+		}
+		length := int64(binary.BigEndian.Uint16(marker[2:]))
+		if length < 2 {
+			return nil, nil
+		}
+		if marker[1] == 0xe1 && length > int64(len(exifHeader))+8 {
+			segment := make([]byte, length-2)
+			if _, err = io.ReadFull(file, segment); err != nil {
+				return nil, nil
+			}
+			if bytes.HasPrefix(segment, exifHeader) {
+				return newExifData(segment[len(exifHeader):], offset+4+int64(len(exifHeader))), nil
+			}
+		} else if _, err = file.Seek(length-2, io.SeekCurrent); err != nil {
+			return nil, err
+		}
+		offset += 2 + length
+	}
+}
+
+func newExifData(tiff []byte, offset int64) *exifData {
+	if len(tiff) < 8 {
+		return nil
+	}
+	exif := &exifData{tiff: tiff, offset: offset}
+	switch string(tiff[:2]) {
+	case "II":
+		exif.order = binary.LittleEndian
+	case "MM":
+		exif.order = binary.BigEndian
+	default:
+		return nil
+	}
+	return exif
+}
+
+// ifd0 is the offset of the first IFD
+func (e *exifData) ifd0() int {
+	return int(e.order.Uint32(e.tiff[4:]))
+}
+
+// entries of the IFD at offset
+func (e *exifData) entries(offset int) []exifEntry {
+	if offset <= 0 || offset+2 > len(e.tiff) {
+		return nil
+	}
+	count := int(e.order.Uint16(e.tiff[offset:]))
+	entries := make([]exifEntry, 0, count)
+	for i := 0; i < count; i++ {
+		at := offset + 2 + i*12
+		if at+12 > len(e.tiff) {
+			break
+		}
+		entries = append(entries, exifEntry{
+			tag:   e.order.Uint16(e.tiff[at:]),
+			kind:  e.order.Uint16(e.tiff[at+2:]),
+			count: e.order.Uint32(e.tiff[at+4:]),
+			at:    at,
+		})
+	}
+	return entries
+}
+
+// find an entry of the IFD at offset
+func (e *exifData) find(offset int, tag uint16) (exifEntry, bool) {
+	for _, entry := range e.entries(offset) {
+		if entry.tag == tag {
+			return entry, true
+		}
+	}
+	return exifEntry{}, false
+}
+
+// short is the value of a SHORT entry
+func (e *exifData) short(entry exifEntry) int {
+	return int(e.order.Uint16(e.tiff[entry.at+8:]))
+}
+
+// value is the bytes of an entry, within it if 4 or less, else at its offset
+func (e *exifData) value(entry exifEntry) []byte {
+	size := exifSizes[entry.kind] * int(entry.count)
+	if size == 0 || entry.count > 1<<20 {
+		return nil
+	}
+	at := entry.at + 8
+	if size > 4 {
+		at = int(e.order.Uint32(e.tiff[at:]))
+	}
+	if at < 0 || at+size > len(e.tiff) {
+		return nil
+	}
+	return e.tiff[at : at+size]
+}
+
+// ascii is the text of an ASCII entry
+func (e *exifData) ascii(entry exifEntry) string {
+	if entry.kind != exifASCII {
+		return ""
+	}
+	return strings.TrimSpace(strings.TrimRight(string(e.value(entry)), "\x00"))
+}
+
+// number is the (first) value of an integer entry
+func (e *exifData) number(entry exifEntry) (int, bool) {
+	value := e.value(entry)
+	switch {
+	case entry.kind == exifShort && len(value) >= 2:
+		return int(e.order.Uint16(value)), true
+	case (entry.kind == exifLong || entry.kind == exifSLong) && len(value) >= 4:
+		if entry.kind == exifSLong {
+			return int(int32(e.order.Uint32(value))), true
+		}
+		return int(e.order.Uint32(value)), true
+	}
+	return 0, false
+}
+
+// rationals are the values of a RATIONAL or SRATIONAL entry
+func (e *exifData) rationals(entry exifEntry) []float64 {
+	if entry.kind != exifRational && entry.kind != exifSRational {
+		return nil
+	}
+	value := e.value(entry)
+	numbers := make([]float64, 0, len(value)/8)
+	for i := 0; i+8 <= len(value); i += 8 {
+		n, d := float64(e.order.Uint32(value[i:])), float64(e.order.Uint32(value[i+4:]))
+		if entry.kind == exifSRational {
+			n, d = float64(int32(e.order.Uint32(value[i:]))), float64(int32(e.order.Uint32(value[i+4:])))
+		}
+		if d == 0 {
+			numbers = append(numbers, 0)
+		} else {
+			numbers = append(numbers, n/d)
+		}
+	}
+	return numbers
+}
+
+// ExifOrientation is the EXIF orientation of an image (1 to 8), 1 if it has none
+func ExifOrientation(path string) int {
+	exif, err := readExif(path)
+	if err != nil || exif == nil {
+		return 1
+	}
+	entry, ok := exif.find(exif.ifd0(), exifOrientationTag)
+	if !ok || entry.kind != exifShort {
+		return 1
+	}
+	if orientation := exif.short(entry); orientation >= 1 && orientation <= 8 {
+		return orientation
+	}
+	return 1
+}
+
+// SetExifOrientation changes the orientation of a JPEG or TIFF, without touching the image.
+// A JPEG without EXIF data is given it.
+func SetExifOrientation(path string, orientation int) error {
+	exif, err := readExif(path)
+	if err != nil {
+		return err
+	}
+	if exif == nil {
+		return insertExifOrientation(path, orientation)
+	}
+	entry, ok := exif.find(exif.ifd0(), exifOrientationTag)
+	if !ok || entry.kind != exifShort {
+		return ErrNoOrientation
+	}
+	value := make([]byte, 2)
+	exif.order.PutUint16(value, uint16(orientation))
+	file, err := os.OpenFile(path, os.O_WRONLY, 0)
+	if err != nil {
+		return err
+	}
+	if _, err = file.WriteAt(value, exif.offset+int64(entry.at)+8); err != nil {
+		_ = file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// insertExifOrientation adds EXIF data of only the orientation to a JPEG
+func insertExifOrientation(path string, orientation int) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if len(content) < 4 || content[0] != 0xff || content[1] != 0xd8 {
+		return errors.New("only a JPEG can be given EXIF data")
+	}
+	updated, err := jpegWithOrientation(content, orientation)
+	if err != nil {
+		return err
+	}
+	return replaceFile(path, updated)
+}
+
+// jpegWithOrientation adds an APP1 of only the orientation to a JPEG,
+// after the SOI, and any JFIF APP0 (that must be first).
+func jpegWithOrientation(content []byte, orientation int) ([]byte, error) {
+	at := 2
+	if len(content) > 6 && content[2] == 0xff && content[3] == 0xe0 {
+		at += 2 + int(binary.BigEndian.Uint16(content[4:]))
+	}
+	if at > len(content) {
+		return nil, errors.New("the JPEG is truncated, its APP0 is longer than the file")
+	}
+	var app1 bytes.Buffer
+	app1.Write([]byte{0xff, 0xe1, 0, 0})
+	app1.Write(exifHeader)
+	app1.WriteString("MM\x00*")
+	for _, value := range []any{
+		uint32(8), // IFD0
+		uint16(1), // entries
+		uint16(exifOrientationTag), uint16(exifShort), uint32(1), uint16(orientation), uint16(0),
+		uint32(0), // no next IFD
+	} {
+		_ = binary.Write(&app1, binary.BigEndian, value)
+	}
+	segment := app1.Bytes()
+	binary.BigEndian.PutUint16(segment[2:], uint16(len(segment)-2))
+
+	updated := make([]byte, 0, len(content)+len(segment))
+	updated = append(updated, content[:at]...)
+	updated = append(updated, segment...)
+	return append(updated, content[at:]...), nil
+}
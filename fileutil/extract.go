@@ -0,0 +1,358 @@
+package fileutil
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+/*
+
+  File:    extract.go
+  Author:  Bob Shofner
+
+  MIT License - https://opensource.org/license/mit/
+
+  This permission notice shall be included in all copies
+    or substantial portions of the Software.
+
+*/
+/*
+  Description: extract a zip, tar or gzip archive (found from its content),
+  all or some of its entries. Existing files are replaced as a copy does,
+  only by newer entries unless all are to be replaced.
+
+  Archives are not trusted. Entries that are absolute, or climb out (../),
+  and links to outside the destination are refused, as are writes through
+  an existing link to outside. Links are followed as the system does (a link
+  then ..), and checked again at the end, as a later link changes earlier ones. The entries, and the bytes written (not the
+  sizes claimed), may be limited, to stop an archive bomb.
+*/
+
+// ExtractOptions choose the entries extracted, and the existing files replaced
+type ExtractOptions struct {
+	Only       map[string]bool // the entries (and those in folders) extracted, all if nil
+	All        bool            // replace existing files, else only those older than the entry
+	MaxSize    int64           // bytes written, no limit if 0
+	MaxEntries int             // entries, no limit if 0
+}
+
+// ErrUnsafeEntry is an entry, or link, to outside the destination
+var ErrUnsafeEntry = errors.New("outside the destination")
+
+// ErrExtractLimit is an archive larger, or of more entries, than allowed
+var ErrExtractLimit = errors.New("over the extract limit")
+
+// wanted is if an entry is to be extracted, it or a folder it is in is chosen
+func (o ExtractOptions) wanted(name string) bool {
+	if o.Only == nil {
+		return true
+	}
+	name = strings.TrimSuffix(strings.ReplaceAll(name, "\\", "/"), "/")
+	for {
+		if o.Only[name] || o.Only[name+"/"] {
+			return true
+		}
+		slash := strings.LastIndexByte(name, '/')
+		if slash < 0 {
+			return false
+		}
+		name = name[:slash]
+	}
+}
+
+// replaces is if an entry is written over an existing file
+func (o ExtractOptions) replaces(destination string, modified time.Time) bool {
+	info, err := os.Stat(destination)
+	if err != nil || o.All {
+		return true
+	}
+	return modified.After(info.ModTime())
+}
+
+// ExtractArchive extracts an archive into dest, and is the number of files written
+func ExtractArchive(path, dest string, options ExtractOptions, notDone func()) (int, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	head := make([]byte, 512)
+	n, _ := io.ReadFull(file, head)
+	_ = file.Close()
+	head = head[:n]
+	switch {
+	case bytes.HasPrefix(head, []byte("PK\x03\x04")) || bytes.HasPrefix(head, []byte("PK\x05\x06")):
+		z, err := NewUnZipper(path)
+		if err != nil {
+			return 0, err
+		}
+		z.options = options
+		return z.Extract(dest, notDone)
+	case bytes.HasPrefix(head, []byte{0x1f, 0x8b}):
+		z, err := NewUnZGipper(path)
+		if err != nil {
+			return 0, err
+		}
+		z.options = options
+		return z.Extract(dest, notDone)
+	case isTar(head):
+		z, err := NewUnTar(path)
+		if err != nil {
+			return 0, err
+		}
+		z.options = options
+		return z.Extract(dest, notDone)
+	}
+	return 0, ErrNotArchive
+}
+
+// extractor writes the entries of an archive, safely
+type extractor struct {
+	dest    string
+	root    string // dest, its links resolved
+	options ExtractOptions
+	entries int
+	written int64
+	links   map[string]string // symbolic links made, to their targets
+}
+
+func newExtractor(dest string, options ExtractOptions) (*extractor, error) {
+	if err := os.MkdirAll(dest, os.ModePerm); err != nil {
+		return nil, err
+	}
+	root, err := filepath.EvalSymlinks(dest)
+	if err != nil {
+		return nil, err
+	}
+	return &extractor{dest: dest, root: root, options: options, links: make(map[string]string)}, nil
+}
+
+// path is where an entry is written, counting the entries
+func (x *extractor) path(name string) (string, error) {
+	x.entries++
+	if x.options.MaxEntries > 0 && x.entries > x.options.MaxEntries {
+		return "", fmt.Errorf("more than %d entries, %w", x.options.MaxEntries, ErrExtractLimit)
+	}
+	return x.local(name)
+}
+
+// local is the path of a name in dest, an error if it is absolute or climbs out
+func (x *extractor) local(name string) (string, error) {
+	clean := strings.TrimSuffix(strings.ReplaceAll(name, "\\", "/"), "/")
+	if strings.HasPrefix(clean, "/") || !filepath.IsLocal(filepath.FromSlash(clean)) {
+		return "", fmt.Errorf("%s is %w", name, ErrUnsafeEntry)
+	}
+	return filepath.Join(x.dest, filepath.FromSlash(clean)), nil
+}
+
+// realPath is a path with the links of its existing folders resolved
+// (those not yet made can't be links)
+func realPath(path string) (string, error) {
+	existing := path
+	rest := ""
+	for {
+		if _, err := os.Lstat(existing); err == nil {
+			break
+		}
+		parent := filepath.Dir(existing)
+		if parent == existing {
+			break
+		}
+		rest = filepath.Join(filepath.Base(existing), rest)
+		existing = parent
+	}
+	resolved, err := filepath.EvalSymlinks(existing)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(resolved, rest), nil
+}
+
+// inside checks the folder of a path is in the destination, once links are resolved
+func (x *extractor) inside(path string) error {
+	parent, err := realPath(filepath.Dir(path))
+	if err != nil {
+		return err
+	}
+	if !InsideDir(parent, x.root) {
+		return fmt.Errorf("%s is through a link %w", path, ErrUnsafeEntry)
+	}
+	return nil
+}
+
+// follow resolves a link target from dir (links resolved) as the system does,
+// a part at a time, so a link followed by .. is where the link points.
+// A part missing ends it, nothing past it can be reached.
+func follow(dir, target string, depth int) (string, bool, error) {
+	if depth > 40 {
+		return "", false, errors.New("too many links")
+	}
+	for _, part := range strings.Split(filepath.ToSlash(target), "/") {
+		switch part {
+		case "", ".":
+			continue
+		case "..":
+			dir = filepath.Dir(dir)
+			continue
+		}
+		next := filepath.Join(dir, part)
+		info, err := os.Lstat(next)
+		if err != nil {
+			return next, false, nil
+		}
+		if info.Mode()&fs.ModeSymlink == 0 {
+			dir = next
+			continue
+		}
+		link, err := os.Readlink(next)
+		if err != nil {
+			return "", false, err
+		}
+		from := dir
+		if filepath.IsAbs(link) {
+			from = filepath.VolumeName(link) + string(filepath.Separator)
+		}
+		found := false
+		if dir, found, err = follow(from, link, depth+1); err != nil || !found {
+			return dir, false, err
+		}
+	}
+	return dir, true, nil
+}
+
+// points is an error unless a link at path to target stays in the destination
+func (x *extractor) points(path, target string) error {
+	local := filepath.FromSlash(strings.ReplaceAll(target, "\\", "/"))
+	if target == "" || filepath.IsAbs(local) || strings.HasPrefix(local, string(filepath.Separator)) {
+		return fmt.Errorf("link %s to %s is %w", filepath.Base(path), target, ErrUnsafeEntry)
+	}
+	parent, err := realPath(filepath.Dir(path))
+	if err != nil {
+		return err
+	}
+	end, _, err := follow(parent, target, 0)
+	if err != nil || !InsideDir(parent, x.root) || !InsideDir(end, x.root) {
+		return fmt.Errorf("link %s to %s is %w", filepath.Base(path), target, ErrUnsafeEntry)
+	}
+	return nil
+}
+
+// verify checks the links again, once all are made, as a later link
+// may change where an earlier one points. Those outside are removed.
+func (x *extractor) verify() error {
+	var unsafe error
+	for path, target := range x.links {
+		if link, err := os.Readlink(path); err != nil || link != target {
+			continue // replaced by a later entry
+		}
+		if err := x.points(path, target); err != nil {
+			_ = os.Remove(path)
+			if unsafe == nil {
+				unsafe = err
+			}
+		}
+	}
+	return unsafe
+}
+
+// mkdir makes the folder of an entry
+func (x *extractor) mkdir(path string) error {
+	if err := x.inside(filepath.Join(path, "x")); err != nil {
+		return err
+	}
+	return os.MkdirAll(path, os.ModePerm)
+}
+
+// prepare makes the folder of an entry, and removes a link where it is written
+// (so it is written there, not where the link points)
+func (x *extractor) prepare(path string) error {
+	if err := x.inside(path); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return err
+	}
+	if info, err := os.Lstat(path); err == nil && info.Mode()&fs.ModeSymlink != 0 {
+		return os.Remove(path)
+	}
+	return nil
+}
+
+// write creates the file of an entry, the bytes written no more than allowed
+func (x *extractor) write(path string, content io.Reader, modified time.Time) error {
+	if err := x.prepare(path); err != nil {
+		return err
+	}
+	out, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	var n int64
+	if x.options.MaxSize > 0 {
+		n, err = io.CopyN(out, content, x.options.MaxSize-x.written+1)
+		if err == io.EOF {
+			err = nil
+		}
+	} else {
+		n, err = io.Copy(out, content)
+	}
+	x.written += n
+	if err == nil && x.options.MaxSize > 0 && x.written > x.options.MaxSize {
+		err = fmt.Errorf("more than %s, %w", PrettyDiskSize(uint64(x.options.MaxSize)), ErrExtractLimit)
+	}
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(path)
+		return err
+	}
+	_ = os.Chtimes(path, modified, modified)
+	return nil
+}
+
+// symlink makes a symbolic link, if it points inside the destination
+func (x *extractor) symlink(path, target string) error {
+	if err := x.points(path, target); err != nil {
+		return err
+	}
+	if err := x.prepare(path); err != nil {
+		return err
+	}
+	if _, err := os.Lstat(path); err == nil {
+		if err = os.Remove(path); err != nil {
+			return err
+		}
+	}
+	if err := os.Symlink(target, path); err != nil {
+		return err
+	}
+	x.links[path] = target
+	return nil
+}
+
+// hardlink links to an entry already written
+func (x *extractor) hardlink(path, target string) error {
+	existing, err := x.local(target)
+	if err != nil {
+		return fmt.Errorf("link %s to %s is %w", filepath.Base(path), target, ErrUnsafeEntry)
+	}
+	if err = x.inside(existing); err != nil {
+		return err
+	}
+	if err = x.prepare(path); err != nil {
+		return err
+	}
+	if _, err = os.Lstat(path); err == nil {
+		if err = os.Remove(path); err != nil {
+			return err
+		}
+	}
+	return os.Link(existing, path)
+}
@@ -0,0 +1,155 @@
+package fileutil
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeTestZip(t *testing.T, path string, names ...string) {
+	t.Helper()
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := zip.NewWriter(file)
+	for _, name := range names {
+		entry, err := w.CreateHeader(&zip.FileHeader{Name: name, Modified: time.Now()})
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _ = entry.Write([]byte("hello world"))
+	}
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	_ = file.Close()
+}
+
+// writeTestTar writes symbolic links, name then target
+func writeTestTar(t *testing.T, path string, links ...string) {
+	t.Helper()
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := tar.NewWriter(file)
+	for i := 0; i+1 < len(links); i += 2 {
+		header := &tar.Header{Name: links[i], Linkname: links[i+1], Typeflag: tar.TypeSymlink,
+			Mode: 0777, ModTime: time.Now()}
+		if err = w.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	_ = file.Close()
+}
+
+// escapes is if any link below dest resolves outside it
+func escapes(t *testing.T, dest string) bool {
+	t.Helper()
+	root, _ := filepath.EvalSymlinks(dest)
+	out := false
+	_ = filepath.WalkDir(dest, func(path string, d os.DirEntry, err error) error {
+		if err != nil || d.Type()&os.ModeSymlink == 0 {
+			return nil
+		}
+		if resolved, err := filepath.EvalSymlinks(path); err == nil && !InsideDir(resolved, root) {
+			t.Logf("%s resolves to %s", path, resolved)
+			out = true
+		}
+		return nil
+	})
+	return out
+}
+
+func TestExtractZipSlip(t *testing.T) {
+	dir := t.TempDir()
+	archive := filepath.Join(dir, "slip.zip")
+	for _, name := range []string{"../evil.txt", "/abs.txt", "a/../../evil.txt"} {
+		writeTestZip(t, archive, "ok.txt", name)
+		_, err := ExtractArchive(archive, filepath.Join(dir, "out"), ExtractOptions{}, func() {})
+		if !errors.Is(err, ErrUnsafeEntry) {
+			t.Errorf("%s: %v, want ErrUnsafeEntry", name, err)
+		}
+		if _, err = os.Stat(filepath.Join(dir, "evil.txt")); err == nil {
+			t.Fatalf("%s was written outside the destination", name)
+		}
+	}
+}
+
+func TestExtractThroughExistingLink(t *testing.T) {
+	dir := t.TempDir()
+	dest := filepath.Join(dir, "out")
+	if err := os.MkdirAll(dest, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(dir, filepath.Join(dest, "sub")); err != nil {
+		t.Fatal(err)
+	}
+	archive := filepath.Join(dir, "through.zip")
+	writeTestZip(t, archive, "sub/pwn.txt")
+	_, err := ExtractArchive(archive, dest, ExtractOptions{}, func() {})
+	if !errors.Is(err, ErrUnsafeEntry) {
+		t.Errorf("%v, want ErrUnsafeEntry", err)
+	}
+	if _, err = os.Stat(filepath.Join(dir, "pwn.txt")); err == nil {
+		t.Fatal("written through a link outside the destination")
+	}
+}
+
+func TestExtractLinkChain(t *testing.T) {
+	for _, test := range []struct {
+		name  string
+		links []string
+	}{
+		{"outside", []string{"out", "../.."}},
+		{"link then ..", []string{"a", ".", "a/b", ".."}},
+		{"link made after", []string{"c", "x/..", "x", "."}},
+		{"through a link", []string{"a", ".", "d", "a/.."}},
+	} {
+		dir := t.TempDir()
+		archive := filepath.Join(dir, "links.tar")
+		writeTestTar(t, archive, test.links...)
+		dest := filepath.Join(dir, "out")
+		_, err := ExtractArchive(archive, dest, ExtractOptions{}, func() {})
+		if !errors.Is(err, ErrUnsafeEntry) {
+			t.Errorf("%s: %v, want ErrUnsafeEntry", test.name, err)
+		}
+		if escapes(t, dest) {
+			t.Errorf("%s: a link points outside the destination", test.name)
+		}
+	}
+
+	// links inside are kept
+	dir := t.TempDir()
+	archive := filepath.Join(dir, "links.tar")
+	writeTestTar(t, archive, "a", ".", "b", "a/a/c", "sub/up", "..")
+	dest := filepath.Join(dir, "out")
+	if n, err := ExtractArchive(archive, dest, ExtractOptions{}, func() {}); err != nil || n != 3 {
+		t.Errorf("inside: %d, %v, want 3 links", n, err)
+	}
+}
+
+func TestExtractLimits(t *testing.T) {
+	dir := t.TempDir()
+	archive := filepath.Join(dir, "bomb.zip")
+	writeTestZip(t, archive, "a", "b", "c")
+	for i, options := range []ExtractOptions{{MaxEntries: 2}, {MaxSize: 20}} {
+		_, err := ExtractArchive(archive, filepath.Join(dir, fmt.Sprint("out", i)), options, func() {})
+		if !errors.Is(err, ErrExtractLimit) {
+			t.Errorf("%+v: %v, want ErrExtractLimit", options, err)
+		}
+	}
+	options := ExtractOptions{MaxSize: 33, MaxEntries: 3, All: true}
+	if n, err := ExtractArchive(archive, filepath.Join(dir, "all"), options, func() {}); err != nil || n != 3 {
+		t.Errorf("at the limits: %d, %v, want 3 files", n, err)
+	}
+}
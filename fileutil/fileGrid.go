@@ -0,0 +1,186 @@
+package fileutil
+
+import (
+	"fman/element/tappable"
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"image"
+	"runtime"
+	"sync"
+)
+
+/*
+
+  File:    fileGrid.go
+  Author:  Bob Shofner
+
+  MIT License - https://opensource.org/license/mit/
+
+  This permission notice shall be included in all copies
+    or substantial portions of the Software.
+
+*/
+/*
+  Description: the files of a directory as a grid of thumbnails.
+  Images are thumbnailed in the background (most recently shown first),
+  other files are shown by an icon. Selection is that of the DirectoryEntry,
+  as in the list.
+*/
+
+const (
+	// requests beyond this are dropped, oldest first (scrolled past)
+	maxThumbnailQueue = 500
+	// thumbnails kept in memory, beyond this they are read again from the cache
+	maxThumbnailsKept = 1000
+)
+
+type FileGrid struct {
+	widget.GridWrap
+	thumbnails *thumbnailLoader
+}
+
+// NewFileGrid shows the files of dir, those that isImage as thumbnails.
+// Close stops the thumbnailing.
+func NewFileGrid(dir *DirectoryEntry, sel FileSelectFilter, action FileSelectAction,
+	isImage func(name string) bool) *FileGrid {
+
+	grid := &FileGrid{thumbnails: newThumbnailLoader()}
+	grid.ExtendBaseWidget(grid)
+	var clearSelections = func() {
+		for ix := range dir.files {
+			fp := dir.File(ix)
+			if fp.selected {
+				fp.SetSelected(false)
+			}
+		}
+		grid.Refresh()
+	}
+	grid.Length = func() int {
+		return dir.Count()
+	}
+	grid.CreateItem = func() fyne.CanvasObject {
+		thumb := tappable.NewThumbnail(ThumbnailNormal, nil)
+		thumb.OnTapped = fileTapped(dir, sel, action, clearSelections, func(id int) {
+			grid.UpdateItem(id, thumb)
+		})
+		return thumb
+	}
+	grid.UpdateItem = func(id widget.GridWrapItemID, item fyne.CanvasObject) {
+		file := dir.File(id)
+		thumb := item.(*tappable.Thumbnail)
+		thumb.ID = id
+		thumb.Label.SetText(file.DisplayName())
+		thumb.SetSelected(file.IsSelected())
+		thumb.Image.Image = nil
+		switch {
+		case file.IsDir():
+			thumb.Image.Resource = theme.FolderIcon()
+		case isImage(file.DisplayName()):
+			thumb.Image.Resource = theme.FileImageIcon()
+			if img, done := grid.thumbnails.get(file.Name()); done {
+				if img != nil {
+					thumb.Image.Resource = nil
+					thumb.Image.Image = img
+				}
+				break
+			}
+			grid.thumbnails.request(file.Name(), func(img image.Image) {
+				if thumb.ID == id && img != nil {
+					thumb.Image.Resource = nil
+					thumb.Image.Image = img
+					thumb.Image.Refresh()
+				}
+			})
+		default:
+			thumb.Image.Resource = theme.FileIcon()
+		}
+		thumb.Image.Refresh()
+	}
+	return grid
+}
+
+// Close stops the thumbnailing
+func (g *FileGrid) Close() {
+	g.thumbnails.close()
+}
+
+type thumbnailRequest struct {
+	path string
+	done func(image.Image)
+}
+
+// thumbnailLoader makes thumbnails with a few workers, most recent request first
+type thumbnailLoader struct {
+	lock     sync.Mutex
+	ready    *sync.Cond
+	requests []thumbnailRequest
+	made     map[string]image.Image // nil if one can't be made
+	closed   bool
+}
+
+func newThumbnailLoader() *thumbnailLoader {
+	loader := &thumbnailLoader{made: make(map[string]image.Image)}
+	loader.ready = sync.NewCond(&loader.lock)
+	for i := 0; i < min(max(runtime.NumCPU()/2, 1), 4); i++ {
+		go loader.work()
+	}
+	return loader
+}
+
+// get is a thumbnail already made, or tried
+func (l *thumbnailLoader) get(path string) (image.Image, bool) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	img, ok := l.made[path]
+	return img, ok
+}
+
+// request a thumbnail, done is called (in the fyne thread) when made
+func (l *thumbnailLoader) request(path string, done func(image.Image)) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if len(l.requests) >= maxThumbnailQueue {
+		l.requests = l.requests[1:]
+	}
+	l.requests = append(l.requests, thumbnailRequest{path: path, done: done})
+	l.ready.Signal()
+}
+
+func (l *thumbnailLoader) close() {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.closed = true
+	l.requests = nil
+	l.ready.Broadcast()
+}
+
+func (l *thumbnailLoader) work() {
+	for {
+		l.lock.Lock()
+		for len(l.requests) == 0 && !l.closed {
+			l.ready.Wait()
+		}
+		if l.closed {
+			l.lock.Unlock()
+			return
+		}
+		request := l.requests[len(l.requests)-1]
+		l.requests = l.requests[:len(l.requests)-1]
+		img, ok := l.made[request.path]
+		l.lock.Unlock()
+
+		if !ok {
+			img, _ = Thumbnail(request.path, ThumbnailNormal)
+			l.lock.Lock()
+			if len(l.made) >= maxThumbnailsKept {
+				clear(l.made)
+			}
+			l.made[request.path] = img
+			l.lock.Unlock()
+		}
+		fyne.Do(func() {
+			request.done(img)
+		})
+	}
+}
@@ -38,6 +38,18 @@ func NewFileList(path string, sel FileSelectFilter, action FileSelectAction,
 	if err != nil {
 		return nil, nil, err
 	}
+	return newFileList(dir, sel, action, pretty), dir, nil
+}
+
+// NewVirtualFileList lists (found) paths below root as if they were a single directory.
+func NewVirtualFileList(root string, paths []string, sel FileSelectFilter, action FileSelectAction,
+	pretty func(name string, info fs.FileInfo, err error) string) (*CustomList, *DirectoryEntry) {
+	dir := NewVirtualDirectoryView(root, paths, sel)
+	return newFileList(dir, sel, action, pretty), dir
+}
+
+func newFileList(dir *DirectoryEntry, sel FileSelectFilter, action FileSelectAction,
+	pretty func(name string, info fs.FileInfo, err error) string) *CustomList {
 	dtFormat = sel.DtFormat
 	if pretty == nil { // use standard display
 		pretty = ls_al
@@ -65,41 +77,9 @@ func NewFileList(path string, sel FileSelectFilter, action FileSelectAction,
 			// build the unique tappable Label
 			tl := tappable.NewLabel("", nil)
 			co := container.NewHBox(widget.NewIcon(theme.FileIcon()), tl)
-			tl.OnTapped = func(t tappable.Tapper, id int, pe *fyne.PointEvent) {
-				// fmt.Println("create a new label", t, " tap on", id)
-				file := dir.File(id) // current file POINTER
-				switch t {
-				case tappable.Primary:
-					if sel.FileType == File && file.IsDir() { // must have File
-						break
-					}
-					if sel.Multiple { // allow many selections
-						file.SetSelected(!file.selected)
-						fileList.UpdateItem(id, co)
-					} else { // only a single File or Dir, toggle select
-						if !file.selected {
-							clearSelections()
-							file.SetSelected(true)
-						} else {
-							file.SetSelected(false)
-						}
-						fileList.UpdateItem(id, co)
-					}
-					file.index = id
-					action.OnClick(*file)
-				case tappable.Double:
-					if file.IsDir() {
-						clearSelections()
-					}
-					if action.OnDoubleClick != nil {
-						action.OnDoubleClick(*file)
-					}
-				case tappable.Secondary:
-					if action.OnSecondaryClick != nil {
-						action.OnSecondaryClick(*file, pe)
-					}
-				}
-			}
+			tl.OnTapped = fileTapped(dir, sel, action, clearSelections, func(id int) {
+				fileList.UpdateItem(id, co)
+			})
 			return co
 		}
 	// update
@@ -135,7 +115,47 @@ func NewFileList(path string, sel FileSelectFilter, action FileSelectAction,
 		}
 	}
 
-	return fileList, dir, nil
+	return fileList
+}
+
+// fileTapped selects (or opens) the file tapped in a list or grid,
+// update shows a change to its selection
+func fileTapped(dir *DirectoryEntry, sel FileSelectFilter, action FileSelectAction,
+	clearSelections func(), update func(id int)) tappable.OnTap {
+	return func(t tappable.Tapper, id int, pe *fyne.PointEvent) {
+		file := dir.File(id) // current file POINTER
+		switch t {
+		case tappable.Primary:
+			if sel.FileType == File && file.IsDir() { // must have File
+				break
+			}
+			if sel.Multiple { // allow many selections
+				file.SetSelected(!file.selected)
+				update(id)
+			} else { // only a single File or Dir, toggle select
+				if !file.selected {
+					clearSelections()
+					file.SetSelected(true)
+				} else {
+					file.SetSelected(false)
+				}
+				update(id)
+			}
+			file.index = id
+			action.OnClick(*file)
+		case tappable.Double:
+			if file.IsDir() {
+				clearSelections()
+			}
+			if action.OnDoubleClick != nil {
+				action.OnDoubleClick(*file)
+			}
+		case tappable.Secondary:
+			if action.OnSecondaryClick != nil {
+				action.OnSecondaryClick(*file, pe)
+			}
+		}
+	}
 }
 
 // ls_al. LINUX ls -Al
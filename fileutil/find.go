@@ -0,0 +1,159 @@
+package fileutil
+
+import (
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+/*
+
+  File:    find.go
+  Author:  Bob Shofner
+
+  MIT License - https://opensource.org/license/mit/
+
+  This permission notice shall be included in all copies
+    or substantial portions of the Software.
+
+*/
+/*
+  Description: walk a directory tree, selecting files by type, size, date and extension.
+*/
+
+// DefaultExclude are directories not normally worth searching
+var DefaultExclude = []string{".git", "node_modules"}
+
+type FindFilter struct {
+	FileType    FileSelectType // Any, File or Dir
+	MinSize     int64          // 0 == no limit
+	MaxSize     int64          // 0 == no limit
+	After       time.Time      // modified after, zero == no limit
+	Before      time.Time      // modified before, zero == no limit
+	Extensions  []string       // ".go", "txt" ...
+	MaxDepth    int            // 0 == no limit, 1 == only the starting directory
+	Exclude     []string       // glob patterns of directory names to skip
+	FollowLinks bool
+}
+
+// Match checks the FileInfo against the filter criteria
+func (f *FindFilter) Match(info fs.FileInfo) bool {
+	switch f.FileType {
+	case File:
+		if info.IsDir() {
+			return false
+		}
+	case Dir:
+		if !info.IsDir() {
+			return false
+		}
+	}
+	// size and extension only apply to files
+	if info.IsDir() {
+		if f.MinSize > 0 || f.MaxSize > 0 || len(f.Extensions) > 0 {
+			return false
+		}
+	} else {
+		if f.MinSize > 0 && info.Size() < f.MinSize {
+			return false
+		}
+		if f.MaxSize > 0 && info.Size() > f.MaxSize {
+			return false
+		}
+		if len(f.Extensions) > 0 && !f.hasExtension(info.Name()) {
+			return false
+		}
+	}
+	if !f.After.IsZero() && !info.ModTime().After(f.After) {
+		return false
+	}
+	if !f.Before.IsZero() && !info.ModTime().Before(f.Before) {
+		return false
+	}
+	return true
+}
+
+func (f *FindFilter) hasExtension(name string) bool {
+	ext := strings.ToLower(filepath.Ext(name))
+	for _, e := range f.Extensions {
+		e = strings.ToLower(strings.TrimSpace(e))
+		if !strings.HasPrefix(e, ".") {
+			e = "." + e
+		}
+		if e == ext {
+			return true
+		}
+	}
+	return false
+}
+
+// Excluded checks a directory name against the Exclude patterns
+func (f *FindFilter) Excluded(name string) bool {
+	for _, pattern := range f.Exclude {
+		if match, _ := filepath.Match(strings.TrimSpace(pattern), name); match {
+			return true
+		}
+	}
+	return false
+}
+
+// FindWalk calls fn for every entry below root that passes the filter.
+// fn returns io.EOF to stop the walk early.
+func FindWalk(root string, filter FindFilter, fn func(string, fs.FileInfo) error) error {
+	visited := make(map[string]bool)
+	err := findWalk(root, 0, &filter, visited, fn)
+	if err == io.EOF {
+		return nil
+	}
+	return err
+}
+
+func findWalk(dir string, depth int, filter *FindFilter, visited map[string]bool,
+	fn func(string, fs.FileInfo) error) error {
+	// guard against symbolic link loops
+	if real, err := filepath.EvalSymlinks(dir); err == nil {
+		if visited[real] {
+			return nil
+		}
+		visited[real] = true
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil // unreadable, skip it
+	}
+	level := depth + 1
+	for _, entry := range entries {
+		path := filepath.Join(dir, entry.Name())
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		isLink := info.Mode()&fs.ModeSymlink != 0
+		if isLink && filter.FollowLinks {
+			if target, err := os.Stat(path); err == nil {
+				info = target
+			}
+		}
+		if info.IsDir() && filter.Excluded(entry.Name()) {
+			continue // not a result, nor searched
+		}
+		if filter.Match(info) {
+			if err := fn(path, info); err != nil {
+				return err
+			}
+		}
+		if !info.IsDir() || (isLink && !filter.FollowLinks) {
+			continue
+		}
+		if filter.MaxDepth > 0 && level >= filter.MaxDepth {
+			continue
+		}
+		if err := findWalk(path, level, filter, visited, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+package fileutil
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+)
+
+/*
+
+  File:    grep.go
+  Author:  Bob Shofner
+
+  MIT License - https://opensource.org/license/mit/
+
+  This permission notice shall be included in all copies
+    or substantial portions of the Software.
+
+*/
+/*
+  Description: search the contents of a file for text (literal or regular expression).
+*/
+
+// ErrBinaryFile is returned by Grep when a file looks like binary data.
+var ErrBinaryFile = errors.New("binary file")
+
+// ErrFileTooLarge is returned by Grep when a file exceeds the maximum size.
+var ErrFileTooLarge = errors.New("file too large")
+
+// GrepHit is a single matching line. Line is 1 based, Col1 and Col2 are the
+// byte offsets of the match in Text.
+type GrepHit struct {
+	Line int
+	Col1 int
+	Col2 int
+	Text string
+}
+
+type Grepper struct {
+	find    string
+	literal bool
+	re      *regexp.Regexp
+	MaxSize int64 // 0 is no limit
+}
+
+// NewGrepper prepares the search criteria used by Grep.
+func NewGrepper(find string, isRegEx, caseSensitive bool, maxSize int64) (*Grepper, error) {
+	g := &Grepper{
+		find:    find,
+		literal: !isRegEx,
+		MaxSize: maxSize,
+	}
+	expr := find
+	if g.literal {
+		if caseSensitive {
+			return g, nil
+		}
+		// not a search of the lower case line, its offsets may differ ('İ')
+		expr = regexp.QuoteMeta(find)
+	}
+	if !caseSensitive {
+		expr = "(?i)" + expr
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+	g.re = re
+	return g, nil
+}
+
+// Literal is the text searched for, when it is not a regular expression
+func (g *Grepper) Literal() (string, bool) {
+	return g.find, g.literal
+}
+
+// Match finds the first match in a line. -1 == not found.
+func (g *Grepper) Match(line string) (int, int) {
+	if g.literal && g.find == "" {
+		return -1, -1
+	}
+	if g.re != nil {
+		cols := g.re.FindStringIndex(line)
+		if cols == nil {
+			return -1, -1
+		}
+		return cols[0], cols[1]
+	}
+	ix := strings.Index(line, g.find)
+	if ix < 0 {
+		return -1, -1
+	}
+	return ix, ix + len(g.find)
+}
+
+// Grep returns (up to max) matching lines of a file.
+func (g *Grepper) Grep(path string, max int) (hits []GrepHit, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if g.MaxSize > 0 && info.Size() > g.MaxSize {
+		return nil, ErrFileTooLarge
+	}
+	reader := bufio.NewReaderSize(f, 8192)
+	if IsBinary(reader) {
+		return nil, ErrBinaryFile
+	}
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	n := 0
+	for scanner.Scan() {
+		n++
+		line := scanner.Text()
+		col1, col2 := g.Match(line)
+		if col1 < 0 {
+			continue
+		}
+		hits = append(hits, GrepHit{Line: n, Col1: col1, Col2: col2, Text: line})
+		if max > 0 && len(hits) >= max {
+			break
+		}
+	}
+	return hits, scanner.Err()
+}
+
+// IsBinary peeks at the start of a file for a NUL byte.
+func IsBinary(reader *bufio.Reader) bool {
+	head, err := reader.Peek(8000)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return false
+	}
+	return bytes.IndexByte(head, 0) > -1
+}
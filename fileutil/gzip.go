@@ -3,8 +3,6 @@ package fileutil
 import (
 	"archive/tar"
 	"compress/gzip"
-	"io"
-	"log"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -28,9 +26,10 @@ import (
  */
 
 type GZipper struct {
-	gzFile string
-	reader *gzip.Reader
-	target *os.File
+	gzFile  string
+	reader  *gzip.Reader
+	target  *os.File
+	options ExtractOptions
 }
 
 func NewUnZGipper(gzFile string) (*GZipper, error) {
@@ -72,45 +71,36 @@ func (z *GZipper) Extract(dest string, notDone func()) (int, error) {
 	}
 	switch strings.ToLower(x) {
 	case ".tgz": // uncompress and extract in single operation
-		fakeTar := &Tar{}
+		fakeTar := &Tar{options: z.options}
 		fakeTar.reader = tar.NewReader(z.reader)
 		return fakeTar.Extract(dest, notDone)
 	default:
 		switch strings.ToLower(filepath.Ext(name)) {
 		case ".tar":
-			fakeTar := &Tar{}
+			fakeTar := &Tar{options: z.options}
 			fakeTar.reader = tar.NewReader(z.reader)
 			return fakeTar.Extract(dest, notDone)
 		default:
-			_ = os.MkdirAll(dest, os.ModePerm)
-			destination := filepath.Join(dest, name)
-			log.Println("destination", destination, ", name", name, ", time", modTime)
-			out, e := os.Create(destination)
-			if e != nil {
-				return 0, e
+			x, err := newExtractor(dest, z.options)
+			if err != nil {
+				return 0, err
+			}
+			destination, err := x.path(name)
+			if err != nil {
+				return 0, err
 			}
-			defer func() {
-				_ = out.Close()
-				_ = os.Chtimes(destination, modTime, modTime)
-			}()
-			b := make([]byte, 4096)
-			n := 0
-			for {
-				notDone()
-				nb, ee := z.reader.Read(b)
-				if ee == io.EOF {
-					break
-				}
-				n += nb
-				nw, _ := out.Write(b[:nb])
-				if nb != nw {
-					break
-				}
+			if !z.options.wanted(name) || !z.options.replaces(destination, modTime) {
+				return 0, nil
+			}
+			notDone()
+			if err = x.write(destination, z.reader, modTime); err != nil {
+				return 0, err
 			}
 			return 1, nil
 		}
 	}
 }
+
 func (z *GZipper) Compress(parent string, files []string, notDone func()) error {
 	defer func() {
 		_ = z.target.Close()
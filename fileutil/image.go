@@ -0,0 +1,372 @@
+package fileutil
+
+import (
+	"errors"
+	"fmt"
+	"github.com/fyne-io/oksvg"
+	"github.com/srwiley/rasterx"
+	_ "golang.org/x/image/bmp"
+	_ "golang.org/x/image/tiff"
+	_ "golang.org/x/image/webp"
+	"image"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+/*
+
+  File:    image.go
+  Author:  Bob Shofner
+
+  MIT License - https://opensource.org/license/mit/
+
+  This permission notice shall be included in all copies
+    or substantial portions of the Software.
+
+*/
+/*
+  Description: decode images upright (by their EXIF orientation), rotate them,
+  and save a rotation.
+
+  The 8 EXIF orientations are the transforms, from the stored image to the
+  upright one, of mirroring and quarter turns. Each is kept as the matrix
+  that maps (x, y), y down, to the upright (x', y').
+
+  PNG, JPEG, GIF, BMP, TIFF and WebP images are decoded, and SVG drawn.
+*/
+
+// images with more pixels than this aren't decoded
+const maxImagePixels = 120 * 1000 * 1000
+
+// SVG images are drawn with the longer side at least svgMinSide, at most svgMaxSide
+const (
+	svgMinSide = 1024
+	svgMaxSide = 4096
+)
+
+var orientations = [9][4]int{
+	{},             // unused
+	{1, 0, 0, 1},   // 1 as stored
+	{-1, 0, 0, 1},  // 2 mirrored horizontally
+	{-1, 0, 0, -1}, // 3 rotated 180
+	{1, 0, 0, -1},  // 4 mirrored vertically
+	{0, 1, 1, 0},   // 5 mirrored horizontally, rotated 270 clockwise
+	{0, -1, 1, 0},  // 6 rotated 90 clockwise
+	{0, -1, -1, 0}, // 7 mirrored horizontally, rotated 90 clockwise
+	{0, 1, -1, 0},  // 8 rotated 270 clockwise
+}
+
+// RotateOrientation is the orientation after turning clockwise, quarter turns
+// (negative for anticlockwise).
+func RotateOrientation(orientation, quarterTurns int) int {
+	if orientation < 1 || orientation > 8 {
+		orientation = 1
+	}
+	m := orientations[orientation]
+	for turns := ((quarterTurns % 4) + 4) % 4; turns > 0; turns-- {
+		// (x, y) -> (-y, x)
+		m = [4]int{-m[2], -m[3], m[0], m[1]}
+	}
+	for o := 1; o <= 8; o++ {
+		if orientations[o] == m {
+			return o
+		}
+	}
+	return 1
+}
+
+// OrientImage transforms an image by an orientation
+func OrientImage(img image.Image, orientation int) image.Image {
+	if orientation <= 1 || orientation > 8 {
+		return img
+	}
+	m := orientations[orientation]
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	ow, oh := w, h
+	if m[0] == 0 {
+		ow, oh = h, w
+	}
+	// the matrix moves the image about the origin, this puts it back
+	offset := func(a, b int) int {
+		at := 0
+		if a < 0 {
+			at += w - 1
+		}
+		if b < 0 {
+			at += h - 1
+		}
+		return at
+	}
+	dx, dy := offset(m[0], m[1]), offset(m[2], m[3])
+	src := toRGBA(img)
+	out := image.NewRGBA(image.Rect(0, 0, ow, oh))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			from := src.PixOffset(x, y)
+			to := out.PixOffset(m[0]*x+m[1]*y+dx, m[2]*x+m[3]*y+dy)
+			copy(out.Pix[to:to+4], src.Pix[from:from+4])
+		}
+	}
+	return out
+}
+
+// toRGBA is the image as RGBA, at the origin
+func toRGBA(img image.Image) *image.RGBA {
+	if rgba, ok := img.(*image.RGBA); ok && rgba.Bounds().Min == (image.Point{}) {
+		return rgba
+	}
+	bounds := img.Bounds()
+	rgba := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(rgba, rgba.Bounds(), img, bounds.Min, draw.Src)
+	return rgba
+}
+
+// DecodeImage reads an image, upright, and its format (as "jpeg", "png", "svg")
+func DecodeImage(path string) (image.Image, string, error) {
+	name := filepath.Base(path)
+	if strings.EqualFold(filepath.Ext(path), ".svg") {
+		img, err := decodeSVG(path)
+		if err != nil {
+			return nil, "", fmt.Errorf("%s: %w", name, err)
+		}
+		return img, "svg", nil
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, "", err
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+	config, format, err := image.DecodeConfig(file)
+	if err == nil && config.Width*config.Height > maxImagePixels {
+		err = fmt.Errorf("%d x %d is too large to show", config.Width, config.Height)
+	}
+	if err == nil {
+		if _, err = file.Seek(0, io.SeekStart); err == nil {
+			var img image.Image
+			if img, format, err = image.Decode(file); err == nil {
+				return OrientImage(img, ExifOrientation(path)), format, nil
+			}
+		}
+	}
+	if errors.Is(err, image.ErrFormat) {
+		err = errors.New("not an image format that can be shown")
+	}
+	return nil, "", fmt.Errorf("%s: %w", name, err)
+}
+
+// decodeSVG draws an SVG, at its own size if not too small or large
+func decodeSVG(path string) (image.Image, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+	icon, err := oksvg.ReadIconStream(file, oksvg.IgnoreErrorMode)
+	if err != nil {
+		return nil, err
+	}
+	box := icon.ViewBox
+	if box.W <= 0 || box.H <= 0 {
+		return nil, errors.New("the SVG has no size")
+	}
+	scale := 1.0
+	if side := max(box.W, box.H); side < svgMinSide {
+		scale = svgMinSide / side
+	} else if side > svgMaxSide {
+		scale = svgMaxSide / side
+	}
+	width, height := int(math.Ceil(box.W*scale)), int(math.Ceil(box.H*scale))
+	icon.Transform = rasterx.Identity.Scale(scale, scale).Translate(-box.X, -box.Y)
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	scanner := rasterx.NewScannerGV(width, height, img, img.Bounds())
+	return img, drawSVG(icon, rasterx.NewDasher(width, height, scanner))
+}
+
+// drawSVG recovers from drawing a malformed SVG
+func drawSVG(icon *oksvg.SvgIcon, raster *rasterx.Dasher) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("the SVG can't be drawn: %v", r)
+		}
+	}()
+	icon.Draw(raster, 1)
+	return nil
+}
+
+// Animation is an animated GIF, each frame drawn over the image so far
+type Animation struct {
+	gif      *gif.GIF
+	frame    int // shown, -1 before the first
+	loops    int // played
+	canvas   *image.RGBA
+	previous *image.RGBA // restored after a frame disposed of to the previous
+}
+
+// DecodeAnimation reads a GIF, nil if it isn't animated
+func DecodeAnimation(path string) (*Animation, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+	g, err := gif.DecodeAll(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filepath.Base(path), err)
+	}
+	if len(g.Image) < 2 {
+		return nil, nil
+	}
+	bounds := image.Rect(0, 0, g.Config.Width, g.Config.Height)
+	for _, frame := range g.Image {
+		bounds = bounds.Union(frame.Bounds())
+	}
+	if bounds.Dx()*bounds.Dy() > maxImagePixels {
+		return nil, fmt.Errorf("%s: %d x %d is too large to show", filepath.Base(path), bounds.Dx(), bounds.Dy())
+	}
+	return &Animation{gif: g, frame: -1, canvas: image.NewRGBA(bounds)}, nil
+}
+
+// Next draws the next frame, and is how long to show it.
+// The same image is drawn on for every frame. False is after the last loop.
+func (a *Animation) Next() (image.Image, time.Duration, bool) {
+	g := a.gif
+	if a.frame >= 0 {
+		// dispose of the frame shown
+		switch g.Disposal[a.frame] {
+		case gif.DisposalBackground:
+			draw.Draw(a.canvas, g.Image[a.frame].Bounds(), image.Transparent, image.Point{}, draw.Src)
+		case gif.DisposalPrevious:
+			if a.previous != nil {
+				copy(a.canvas.Pix, a.previous.Pix)
+			}
+		}
+	}
+	a.frame++
+	if a.frame == len(g.Image) {
+		a.frame = 0
+		a.loops++
+	}
+	if a.frame == 0 {
+		draw.Draw(a.canvas, a.canvas.Bounds(), image.Transparent, image.Point{}, draw.Src)
+	}
+	if g.Disposal[a.frame] == gif.DisposalPrevious {
+		if a.previous == nil {
+			a.previous = image.NewRGBA(a.canvas.Bounds())
+		}
+		copy(a.previous.Pix, a.canvas.Pix)
+	}
+	frame := g.Image[a.frame]
+	draw.Draw(a.canvas, frame.Bounds(), frame, frame.Bounds().Min, draw.Over)
+
+	// as browsers do, very short delays are slowed
+	delay := time.Duration(g.Delay[a.frame]) * 10 * time.Millisecond
+	if delay < 20*time.Millisecond {
+		delay = 100 * time.Millisecond
+	}
+	// LoopCount 0 is forever, -1 once, n is n more times
+	more := g.LoopCount == 0 || (g.LoopCount > 0 && a.loops < g.LoopCount) ||
+		a.frame < len(g.Image)-1
+	return a.canvas, delay, more
+}
+
+// SaveRotation saves an image turned clockwise, quarter turns.
+// JPEG and TIFF images are rotated (losslessly) by their EXIF orientation,
+// PNG images (losslessly) by their pixels. Others can't be saved.
+func SaveRotation(path string, quarterTurns int) error {
+	if quarterTurns%4 == 0 {
+		return nil
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".jpg", ".jpeg", ".jpe", ".jfif", ".tif", ".tiff":
+		return SetExifOrientation(path, RotateOrientation(ExifOrientation(path), quarterTurns))
+	case ".png":
+		img, _, err := DecodeImage(path)
+		if err != nil {
+			return err
+		}
+		return SaveImage(path, OrientImage(img, RotateOrientation(1, quarterTurns)))
+	}
+	return fmt.Errorf("the rotation of %s files can't be saved", filepath.Ext(path))
+}
+
+// SaveImage writes an image, as PNG or JPEG by the extension of path
+func SaveImage(path string, img image.Image) error {
+	return SaveImageWith(path, img, EncodeOptions{})
+}
+
+// EncodeOptions are the JPEG quality (1 to 100, default 92)
+// and number of GIF colors (2 to 256, default 256)
+type EncodeOptions struct {
+	Quality int
+	Colors  int
+}
+
+// SaveImageWith writes an image, as PNG, JPEG or GIF by the extension of path.
+// A JPEG has no transparency, so is drawn over white.
+func SaveImageWith(path string, img image.Image, options EncodeOptions) error {
+	out, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	tmp := out.Name()
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".jpg", ".jpeg", ".jpe", ".jfif":
+		quality := options.Quality
+		if quality < 1 || quality > 100 {
+			quality = 92
+		}
+		err = jpeg.Encode(out, overWhite(img), &jpeg.Options{Quality: quality})
+	case ".png":
+		err = png.Encode(out, img)
+	case ".gif":
+		colors := options.Colors
+		if colors < 2 || colors > 256 {
+			colors = 256
+		}
+		err = gif.Encode(out, img, &gif.Options{NumColors: colors, Quantizer: medianCut{}})
+	default:
+		err = fmt.Errorf("%s images can't be written", filepath.Ext(path))
+	}
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		mode := os.FileMode(0644)
+		if info, statErr := os.Stat(path); statErr == nil {
+			mode = info.Mode().Perm()
+		}
+		_ = os.Chmod(tmp, mode)
+		err = os.Rename(tmp, path)
+	}
+	if err != nil {
+		_ = os.Remove(tmp)
+	}
+	return err
+}
+
+// overWhite is an image drawn over white, if it isn't opaque
+func overWhite(img image.Image) image.Image {
+	if opaque, ok := img.(interface{ Opaque() bool }); ok && opaque.Opaque() {
+		return img
+	}
+	bounds := img.Bounds()
+	flat := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(flat, flat.Bounds(), image.White, image.Point{}, draw.Src)
+	draw.Draw(flat, flat.Bounds(), img, bounds.Min, draw.Over)
+	return flat
+}
@@ -0,0 +1,541 @@
+package fileutil
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/gob"
+	"github.com/fsnotify/fsnotify"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+/*
+
+  File:    index.go
+  Author:  Bob Shofner
+
+  MIT License - https://opensource.org/license/mit/
+
+  This permission notice shall be included in all copies
+    or substantial portions of the Software.
+
+*/
+/*
+  Description: a persistent index of the files below some roots.
+  Names, sizes and times (and optionally the trigrams of text contents)
+  are saved in a gzip'ed gob and kept current with fsnotify.
+*/
+
+const indexVersion = 3
+
+// files larger than this do not have their contents indexed
+var IndexContentMax int64 = 1024 * 1024
+
+type IndexEntry struct {
+	Path    string
+	Size    int64
+	ModTime int64 // unix nanoseconds
+	Mode    fs.FileMode
+	Content bool // its trigrams are indexed
+}
+
+// the on disk form of the index
+type indexFile struct {
+	Version int
+	Roots   []string
+	Content bool
+	Entries []IndexEntry
+	Grams   map[uint32][]int32 // trigram to Entries index
+}
+
+type FileIndex struct {
+	Roots   []string
+	Content bool
+	file    string
+	mu      sync.RWMutex
+	entries map[string]*IndexEntry
+	grams   *postings
+	ready   bool
+	dirty   bool
+	watcher *fsnotify.Watcher
+	done    chan bool
+}
+
+// NewFileIndex loads the saved index (if it has the same roots), and starts
+// a background walk to refresh it, and then watches for changes.
+func NewFileIndex(file string, roots []string, content bool) *FileIndex {
+	idx := &FileIndex{
+		Roots:   roots,
+		Content: content,
+		file:    file,
+		entries: make(map[string]*IndexEntry),
+		grams:   newPostings(),
+		done:    make(chan bool),
+	}
+	if err := idx.load(); err != nil {
+		log.Println("index load", err)
+	}
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		log.Println("index watcher", err)
+	} else {
+		idx.watcher = watcher
+	}
+	go idx.run()
+	return idx
+}
+
+// Close stops watching and saves the index
+func (idx *FileIndex) Close() {
+	select {
+	case <-idx.done:
+		return // already closed
+	default:
+	}
+	close(idx.done)
+	if idx.watcher != nil {
+		_ = idx.watcher.Close()
+	}
+	if err := idx.save(); err != nil {
+		log.Println("index save", err)
+	}
+}
+
+// Covers is true when the index is usable for a search below root
+func (idx *FileIndex) Covers(root string) bool {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	if !idx.ready {
+		return false
+	}
+	for _, r := range idx.Roots {
+		if InsideDir(root, r) {
+			return true
+		}
+	}
+	return false
+}
+
+// Count is the number of indexed paths
+func (idx *FileIndex) Count() int {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	return len(idx.entries)
+}
+
+// Walk is FindWalk using the index in place of the file system.
+// Symbolic links are never followed.
+func (idx *FileIndex) Walk(root string, filter FindFilter, fn func(string, fs.FileInfo) error) error {
+	type found struct {
+		path string
+		info fs.FileInfo
+	}
+	var list []found
+	idx.mu.RLock()
+	for path, e := range idx.entries {
+		rel, err := filepath.Rel(root, path)
+		if err != nil || rel == "." || !InsideDir(path, root) {
+			continue
+		}
+		dirs := strings.Split(rel, string(filepath.Separator))
+		if filter.MaxDepth > 0 && len(dirs) > filter.MaxDepth {
+			continue
+		}
+		excluded := e.Mode.IsDir() && filter.Excluded(dirs[len(dirs)-1])
+		for _, d := range dirs[:len(dirs)-1] {
+			if filter.Excluded(d) {
+				excluded = true
+				break
+			}
+		}
+		if excluded {
+			continue
+		}
+		info := indexInfo{e}
+		if filter.Match(info) {
+			list = append(list, found{path: path, info: info})
+		}
+	}
+	idx.mu.RUnlock()
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].path < list[j].path
+	})
+	for _, f := range list {
+		if err := fn(f.path, f.info); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// Candidates is true for the files that may contain the text (case insensitive),
+// those with trigrams of it, and those without their content indexed
+// (too large, or binary) that must be searched.
+// nil means the index is unable to tell.
+func (idx *FileIndex) Candidates(text string) func(path string) bool {
+	grams := trigrams([]byte(text))
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	if !idx.Content || !idx.ready || len(grams) < 1 {
+		return nil
+	}
+	var result map[int32]bool
+	for g := range grams {
+		next := make(map[int32]bool)
+		for _, id := range idx.grams.files[g] {
+			if result == nil || result[id] {
+				next[id] = true
+			}
+		}
+		result = next
+		if len(result) < 1 {
+			break
+		}
+	}
+	return func(path string) bool {
+		idx.mu.RLock()
+		defer idx.mu.RUnlock()
+		if id, ok := idx.grams.ids[path]; ok && result[id] {
+			return true
+		}
+		e := idx.entries[path]
+		return e == nil || !e.Content
+	}
+}
+
+// run builds a fresh index then watches for changes
+func (idx *FileIndex) run() {
+	start := time.Now()
+	entries := make(map[string]*IndexEntry)
+	grams := newPostings()
+	for _, root := range idx.Roots {
+		idx.walk(root, entries, grams)
+	}
+	select {
+	case <-idx.done:
+		return
+	default:
+	}
+	idx.mu.Lock()
+	idx.entries = entries
+	idx.grams = grams
+	idx.ready = true
+	idx.dirty = true
+	idx.mu.Unlock()
+	log.Printf("index of %v, %d files in %s\n", idx.Roots, len(entries), time.Since(start))
+	idx.watch()
+}
+
+// walk adds everything below dir, and watches the directories
+func (idx *FileIndex) walk(dir string, entries map[string]*IndexEntry, grams *postings) {
+	_ = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		select {
+		case <-idx.done:
+			return io.EOF
+		default:
+		}
+		if err != nil {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		idx.add(path, info, entries, grams)
+		if d.IsDir() && idx.watcher != nil {
+			if err := idx.watcher.Add(path); err != nil {
+				log.Println("index watch", path, err)
+			}
+		}
+		return nil
+	})
+}
+
+func (idx *FileIndex) add(path string, info fs.FileInfo, entries map[string]*IndexEntry,
+	grams *postings) {
+	entry := &IndexEntry{
+		Path:    path,
+		Size:    info.Size(),
+		ModTime: info.ModTime().UnixNano(),
+		Mode:    info.Mode(),
+	}
+	entries[path] = entry
+	if !idx.Content || !info.Mode().IsRegular() || info.Size() > IndexContentMax {
+		return
+	}
+	content, err := os.ReadFile(path)
+	if err != nil || bytes.IndexByte(content, 0) > -1 {
+		return // unreadable or binary
+	}
+	grams.add(path, trigrams(content))
+	entry.Content = true
+}
+
+// remove a path and anything below it.
+// stale trigrams are left, they only add candidates that Grep rejects.
+func (idx *FileIndex) remove(path string) {
+	delete(idx.entries, path)
+	prefix := path + string(filepath.Separator)
+	for p := range idx.entries {
+		if strings.HasPrefix(p, prefix) {
+			delete(idx.entries, p)
+		}
+	}
+}
+
+// watch applies file system changes, and saves the index when changed
+func (idx *FileIndex) watch() {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+	var events chan fsnotify.Event
+	var errs chan error
+	if idx.watcher != nil {
+		events = idx.watcher.Events
+		errs = idx.watcher.Errors
+	}
+	for {
+		select {
+		case <-idx.done:
+			return
+		case <-ticker.C:
+			if err := idx.save(); err != nil {
+				log.Println("index save", err)
+			}
+		case err, ok := <-errs:
+			if !ok {
+				return
+			}
+			log.Println("index watch", err)
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			idx.event(event)
+		}
+	}
+}
+
+func (idx *FileIndex) event(event fsnotify.Event) {
+	if event.Has(fsnotify.Remove) || event.Has(fsnotify.Rename) {
+		idx.mu.Lock()
+		idx.remove(event.Name)
+		idx.dirty = true
+		idx.mu.Unlock()
+		return
+	}
+	info, err := os.Lstat(event.Name)
+	if err != nil {
+		return
+	}
+	if event.Has(fsnotify.Create) && info.IsDir() {
+		entries := make(map[string]*IndexEntry)
+		grams := newPostings()
+		idx.walk(event.Name, entries, grams)
+		idx.mu.Lock()
+		for p, e := range entries {
+			idx.entries[p] = e
+		}
+		idx.grams.merge(grams)
+		idx.dirty = true
+		idx.mu.Unlock()
+		return
+	}
+	idx.mu.Lock()
+	idx.add(event.Name, info, idx.entries, idx.grams)
+	idx.dirty = true
+	idx.mu.Unlock()
+}
+
+// postings are the files of each trigram, as ids of their paths, in order.
+// Ids of removed files are kept, until the index is rebuilt.
+type postings struct {
+	paths []string         // id to path
+	ids   map[string]int32 // path to id
+	files map[uint32][]int32
+}
+
+func newPostings() *postings {
+	return &postings{ids: make(map[string]int32), files: make(map[uint32][]int32)}
+}
+
+func (p *postings) id(path string) int32 {
+	id, ok := p.ids[path]
+	if !ok {
+		id = int32(len(p.paths))
+		p.paths = append(p.paths, path)
+		p.ids[path] = id
+	}
+	return id
+}
+
+// add the trigrams of a file. A file changed is added again,
+// its old trigrams only add candidates that Grep rejects.
+func (p *postings) add(path string, grams map[uint32]bool) {
+	id := p.id(path)
+	for g := range grams {
+		p.insert(g, id)
+	}
+}
+
+// insert an id in the files of a trigram, new files are last
+func (p *postings) insert(g uint32, id int32) {
+	files := p.files[g]
+	if len(files) < 1 || files[len(files)-1] < id {
+		p.files[g] = append(files, id)
+		return
+	}
+	at := sort.Search(len(files), func(i int) bool {
+		return files[i] >= id
+	})
+	if files[at] == id {
+		return
+	}
+	files = append(files, 0)
+	copy(files[at+1:], files[at:])
+	files[at] = id
+	p.files[g] = files
+}
+
+// merge adds the trigrams of another, of files below a folder created
+func (p *postings) merge(from *postings) {
+	ids := make([]int32, len(from.paths))
+	for i, path := range from.paths {
+		ids[i] = p.id(path)
+	}
+	for g, files := range from.files {
+		for _, id := range files {
+			p.insert(g, ids[id])
+		}
+	}
+}
+
+func (idx *FileIndex) load() error {
+	f, err := os.Open(idx.file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return err
+	}
+	var saved indexFile
+	if err = gob.NewDecoder(zr).Decode(&saved); err != nil {
+		return err
+	}
+	if saved.Version != indexVersion || saved.Content != idx.Content ||
+		strings.Join(saved.Roots, "\n") != strings.Join(idx.Roots, "\n") {
+		return nil // different configuration, rebuild
+	}
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	grams := newPostings()
+	for i := range saved.Entries {
+		idx.entries[saved.Entries[i].Path] = &saved.Entries[i]
+		grams.id(saved.Entries[i].Path) // the ids are the order saved
+	}
+	if saved.Grams != nil {
+		grams.files = saved.Grams
+	}
+	idx.grams = grams
+	idx.ready = true
+	return nil
+}
+
+func (idx *FileIndex) save() error {
+	idx.mu.Lock()
+	if !idx.dirty || !idx.ready {
+		idx.mu.Unlock()
+		return nil
+	}
+	saved := indexFile{
+		Version: indexVersion,
+		Roots:   idx.Roots,
+		Content: idx.Content,
+		Entries: make([]IndexEntry, 0, len(idx.entries)),
+		Grams:   make(map[uint32][]int32, len(idx.grams.files)),
+	}
+	// the files with trigrams first, their ids are the order saved (removed ones dropped)
+	ids := make([]int32, len(idx.grams.paths))
+	for id, path := range idx.grams.paths {
+		ids[id] = -1
+		if e, ok := idx.entries[path]; ok {
+			ids[id] = int32(len(saved.Entries))
+			saved.Entries = append(saved.Entries, *e)
+		}
+	}
+	for path, e := range idx.entries {
+		if _, ok := idx.grams.ids[path]; !ok {
+			saved.Entries = append(saved.Entries, *e)
+		}
+	}
+	for g, files := range idx.grams.files {
+		list := make([]int32, 0, len(files))
+		for _, id := range files {
+			if ids[id] >= 0 {
+				list = append(list, ids[id])
+			}
+		}
+		if len(list) > 0 {
+			saved.Grams[g] = list
+		}
+	}
+	idx.dirty = false
+	idx.mu.Unlock()
+
+	tmp := idx.file + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	zw := gzip.NewWriter(f)
+	err = gob.NewEncoder(zw).Encode(&saved)
+	if e := zw.Close(); err == nil {
+		err = e
+	}
+	if e := f.Close(); err == nil {
+		err = e
+	}
+	if err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, idx.file)
+}
+
+// trigrams of the (lower case) text
+func trigrams(text []byte) map[uint32]bool {
+	text = bytes.ToLower(text)
+	grams := make(map[uint32]bool)
+	for i := 0; i+2 < len(text); i++ {
+		grams[uint32(text[i])<<16|uint32(text[i+1])<<8|uint32(text[i+2])] = true
+	}
+	return grams
+}
+
+// indexInfo is the fs.FileInfo of an IndexEntry
+type indexInfo struct {
+	e *IndexEntry
+}
+
+func (i indexInfo) Name() string       { return filepath.Base(i.e.Path) }
+func (i indexInfo) Size() int64        { return i.e.Size }
+func (i indexInfo) Mode() fs.FileMode  { return i.e.Mode }
+func (i indexInfo) ModTime() time.Time { return time.Unix(0, i.e.ModTime) }
+func (i indexInfo) IsDir() bool        { return i.e.Mode.IsDir() }
+func (i indexInfo) Sys() any           { return nil }
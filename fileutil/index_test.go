@@ -0,0 +1,54 @@
+package fileutil
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// a file too large to have its content indexed is still a candidate
+func TestCandidatesOverContentMax(t *testing.T) {
+	saved := IndexContentMax
+	IndexContentMax = 1024
+	defer func() {
+		IndexContentMax = saved
+	}()
+	root := t.TempDir()
+	small := filepath.Join(root, "small.txt")
+	other := filepath.Join(root, "other.txt")
+	large := filepath.Join(root, "large.txt")
+	if err := os.WriteFile(small, []byte("a needle in a haystack"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(other, []byte("only hay"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	content := strings.Repeat("hay ", 1000) + "needle"
+	if err := os.WriteFile(large, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	idx := NewFileIndex(filepath.Join(t.TempDir(), "index.gz"), []string{root}, true)
+	defer idx.Close()
+	for start := time.Now(); !idx.Covers(root); time.Sleep(10 * time.Millisecond) {
+		if time.Since(start) > 5*time.Second {
+			t.Fatal("index not ready")
+		}
+	}
+
+	candidate := idx.Candidates("needle")
+	if candidate == nil {
+		t.Fatal("no candidates")
+	}
+	if !candidate(small) {
+		t.Error("small file containing the text is not a candidate")
+	}
+	if candidate(other) {
+		t.Error("small file without the text is a candidate")
+	}
+	if !candidate(large) {
+		t.Error("file over IndexContentMax is not a candidate")
+	}
+}
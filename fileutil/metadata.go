@@ -0,0 +1,359 @@
+package fileutil
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+/*
+
+  File:    metadata.go
+  Author:  Bob Shofner
+
+  MIT License - https://opensource.org/license/mit/
+
+  This permission notice shall be included in all copies
+    or substantial portions of the Software.
+
+*/
+/*
+  Description: the metadata of images (camera, exposure, GPS, capture date)
+  from their EXIF data, stripping it, and dating files by it.
+*/
+
+// EXIF tags
+const (
+	tagMake             = 0x010f
+	tagModel            = 0x0110
+	tagDateTime         = 0x0132
+	tagExifIFD          = 0x8769
+	tagGPSIFD           = 0x8825
+	tagExposureTime     = 0x829a
+	tagFNumber          = 0x829d
+	tagISO              = 0x8827
+	tagDateTimeOriginal = 0x9003
+	tagOffsetOriginal   = 0x9011
+	tagFocalLength      = 0x920a
+	tagLensMake         = 0xa433
+	tagLensModel        = 0xa434
+	tagGPSLatitudeRef   = 1
+	tagGPSLatitude      = 2
+	tagGPSLongitudeRef  = 3
+	tagGPSLongitude     = 4
+	tagGPSAltitudeRef   = 5
+	tagGPSAltitude      = 6
+)
+
+const exifTimeFormat = "2006:01:02 15:04:05"
+
+// ImageMetadata is what is known of an image, zero values if not
+type ImageMetadata struct {
+	Width, Height int
+	Camera        string
+	Lens          string
+	Exposure      float64 // seconds
+	FNumber       float64
+	ISO           int
+	FocalLength   float64 // mm
+	Taken         time.Time
+	HasGPS        bool
+	Latitude      float64 // degrees, negative south
+	Longitude     float64 // degrees, negative west
+	Altitude      float64 // meters
+	Orientation   int
+}
+
+// ReadImageMetadata reads the size of an image, and its EXIF data if any
+func ReadImageMetadata(path string) (*ImageMetadata, error) {
+	meta := &ImageMetadata{Orientation: 1}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	config, _, err := image.DecodeConfig(file)
+	_ = file.Close()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filepath.Base(path), err)
+	}
+	meta.Width, meta.Height = config.Width, config.Height
+
+	exif, err := readExif(path)
+	if err != nil || exif == nil {
+		return meta, err
+	}
+	ifd0 := exif.ifd0()
+	var exifIFD, gpsIFD int
+	for _, entry := range exif.entries(ifd0) {
+		switch entry.tag {
+		case tagMake:
+			meta.Camera = exif.ascii(entry)
+		case tagModel:
+			meta.Camera = joinName(meta.Camera, exif.ascii(entry))
+		case tagDateTime:
+			if meta.Taken.IsZero() {
+				meta.Taken, _ = time.ParseInLocation(exifTimeFormat, exif.ascii(entry), time.Local)
+			}
+		case exifOrientationTag:
+			if o, ok := exif.number(entry); ok && o >= 1 && o <= 8 {
+				meta.Orientation = o
+			}
+		case tagExifIFD:
+			exifIFD, _ = exif.number(entry)
+		case tagGPSIFD:
+			gpsIFD, _ = exif.number(entry)
+		}
+	}
+
+	var taken, offset string
+	var lensMake string
+	for _, entry := range exif.entries(exifIFD) {
+		switch entry.tag {
+		case tagExposureTime:
+			meta.Exposure = first(exif.rationals(entry))
+		case tagFNumber:
+			meta.FNumber = first(exif.rationals(entry))
+		case tagISO:
+			meta.ISO, _ = exif.number(entry)
+		case tagDateTimeOriginal:
+			taken = exif.ascii(entry)
+		case tagOffsetOriginal:
+			offset = exif.ascii(entry)
+		case tagFocalLength:
+			meta.FocalLength = first(exif.rationals(entry))
+		case tagLensMake:
+			lensMake = exif.ascii(entry)
+		case tagLensModel:
+			meta.Lens = exif.ascii(entry)
+		}
+	}
+	meta.Lens = joinName(lensMake, meta.Lens)
+	if taken != "" {
+		location := time.Local
+		if t, err := time.Parse("-07:00", offset); err == nil {
+			_, seconds := t.Zone()
+			location = time.FixedZone(offset, seconds)
+		}
+		if t, err := time.ParseInLocation(exifTimeFormat, taken, location); err == nil {
+			meta.Taken = t
+		}
+	}
+
+	var latitude, longitude []float64
+	var north, east, below = true, true, false
+	for _, entry := range exif.entries(gpsIFD) {
+		switch entry.tag {
+		case tagGPSLatitudeRef:
+			north = exif.ascii(entry) != "S"
+		case tagGPSLatitude:
+			latitude = exif.rationals(entry)
+		case tagGPSLongitudeRef:
+			east = exif.ascii(entry) != "W"
+		case tagGPSLongitude:
+			longitude = exif.rationals(entry)
+		case tagGPSAltitudeRef:
+			value := exif.value(entry)
+			below = len(value) > 0 && value[0] == 1
+		case tagGPSAltitude:
+			meta.Altitude = first(exif.rationals(entry))
+		}
+	}
+	if len(latitude) == 3 && len(longitude) == 3 {
+		meta.HasGPS = true
+		meta.Latitude = latitude[0] + latitude[1]/60 + latitude[2]/3600
+		meta.Longitude = longitude[0] + longitude[1]/60 + longitude[2]/3600
+		if !north {
+			meta.Latitude = -meta.Latitude
+		}
+		if !east {
+			meta.Longitude = -meta.Longitude
+		}
+		if below {
+			meta.Altitude = -meta.Altitude
+		}
+	}
+	return meta, nil
+}
+
+func first(numbers []float64) float64 {
+	if len(numbers) == 0 {
+		return 0
+	}
+	return numbers[0]
+}
+
+// joinName joins a maker and model, unless the model includes the maker
+func joinName(maker, model string) string {
+	switch {
+	case maker == "":
+		return model
+	case model == "":
+		return maker
+	case strings.HasPrefix(strings.ToLower(model), strings.ToLower(strings.Fields(maker)[0])):
+		return model
+	}
+	return maker + " " + model
+}
+
+// Fields are the names and values known, for display
+func (m *ImageMetadata) Fields() [][2]string {
+	fields := [][2]string{{"Dimensions", fmt.Sprintf("%d x %d", m.Width, m.Height)}}
+	if m.Orientation > 4 { // turned on its side
+		fields[0][1] = fmt.Sprintf("%d x %d", m.Height, m.Width)
+	}
+	add := func(name, value string) {
+		if value != "" {
+			fields = append(fields, [2]string{name, value})
+		}
+	}
+	if !m.Taken.IsZero() {
+		add("Taken", m.Taken.Format("2006-01-02 15:04:05 -07:00"))
+	}
+	add("Camera", m.Camera)
+	add("Lens", m.Lens)
+	var exposure []string
+	if m.Exposure > 0 {
+		if m.Exposure < 1 {
+			exposure = append(exposure, fmt.Sprintf("1/%.0f s", 1/m.Exposure))
+		} else {
+			exposure = append(exposure, fmt.Sprintf("%g s", m.Exposure))
+		}
+	}
+	if m.FNumber > 0 {
+		exposure = append(exposure, fmt.Sprintf("f/%.1f", m.FNumber))
+	}
+	if m.ISO > 0 {
+		exposure = append(exposure, fmt.Sprintf("ISO %d", m.ISO))
+	}
+	if m.FocalLength > 0 {
+		exposure = append(exposure, fmt.Sprintf("%g mm", math.Round(m.FocalLength*10)/10))
+	}
+	add("Exposure", strings.Join(exposure, ", "))
+	if m.HasGPS {
+		gps := fmt.Sprintf("%.6f, %.6f", m.Latitude, m.Longitude)
+		if m.Altitude != 0 {
+			gps += fmt.Sprintf(" (%.0f m)", m.Altitude)
+		}
+		add("GPS", gps)
+	}
+	return fields
+}
+
+// String is the fields, one a line
+func (m *ImageMetadata) String() string {
+	lines := make([]string, 0)
+	for _, field := range m.Fields() {
+		lines = append(lines, field[0]+": "+field[1])
+	}
+	return strings.Join(lines, "\n")
+}
+
+// SetTimeFromExif sets the modified time of an image to when it was taken
+func SetTimeFromExif(path string) error {
+	meta, err := ReadImageMetadata(path)
+	if err != nil {
+		return err
+	}
+	if meta.Taken.IsZero() {
+		return fmt.Errorf("%s has no EXIF date", filepath.Base(path))
+	}
+	return os.Chtimes(path, meta.Taken, meta.Taken)
+}
+
+// StripMetadata removes the metadata of a JPEG (EXIF, XMP, IPTC and comments,
+// keeping the orientation and color profile) or PNG (text, EXIF and time chunks).
+// The modified time is kept.
+func StripMetadata(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var stripped []byte
+	switch {
+	case bytes.HasPrefix(content, []byte{0xff, 0xd8}):
+		orientation := ExifOrientation(path)
+		if stripped, err = stripJPEG(content); err == nil && orientation != 1 {
+			stripped, err = jpegWithOrientation(stripped, orientation)
+		}
+	case bytes.HasPrefix(content, []byte("\x89PNG\r\n\x1a\n")):
+		stripped, err = stripPNG(content)
+	default:
+		err = errors.New("only the metadata of JPEG and PNG images can be stripped")
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", filepath.Base(path), err)
+	}
+	if err = replaceFile(path, stripped); err != nil {
+		return err
+	}
+	return os.Chtimes(path, info.ModTime(), info.ModTime())
+}
+
+// stripJPEG drops the APPn segments (but JFIF, the ICC profile and Adobe) and comments
+func stripJPEG(content []byte) ([]byte, error) {
+	out := make([]byte, 0, len(content))
+	out = append(out, content[:2]...)
+	at := 2
+	for {
+		if at+4 > len(content) || content[at] != 0xff {
+			return nil, errors.New("the JPEG is malformed")
+		}
+		marker := content[at+1]
+		if marker == 0xda { // start of scan, the rest is image data
+			return append(out, content[at:]...), nil
+		}
+		length := int(binary.BigEndian.Uint16(content[at+2:]))
+		end := at + 2 + length
+		if length < 2 || end > len(content) {
+			return nil, errors.New("the JPEG is malformed")
+		}
+		segment := content[at+4 : end]
+		keep := true
+		switch {
+		case marker == 0xfe: // comment
+			keep = false
+		case marker >= 0xe0 && marker <= 0xef:
+			keep = (marker == 0xe0 && bytes.HasPrefix(segment, []byte("JFIF"))) ||
+				(marker == 0xe2 && bytes.HasPrefix(segment, []byte("ICC_PROFILE"))) ||
+				(marker == 0xee && bytes.HasPrefix(segment, []byte("Adobe")))
+		}
+		if keep {
+			out = append(out, content[at:end]...)
+		}
+		at = end
+	}
+}
+
+// stripPNG drops the text, EXIF and time chunks
+func stripPNG(content []byte) ([]byte, error) {
+	out := make([]byte, 0, len(content))
+	out = append(out, content[:8]...)
+	for at := 8; at < len(content); {
+		if at+12 > len(content) {
+			return nil, errors.New("the PNG is malformed")
+		}
+		length := int(binary.BigEndian.Uint32(content[at:]))
+		end := at + 12 + length
+		if length < 0 || end > len(content) {
+			return nil, errors.New("the PNG is malformed")
+		}
+		switch string(content[at+4 : at+8]) {
+		case "tEXt", "zTXt", "iTXt", "eXIf", "tIME":
+		default:
+			out = append(out, content[at:end]...)
+		}
+		at = end
+	}
+	return out, nil
+}
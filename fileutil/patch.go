@@ -0,0 +1,55 @@
+package fileutil
+
+import (
+	"os"
+	"sort"
+)
+
+/*
+
+  File:    patch.go
+  Author:  Bob Shofner
+
+  MIT License - https://opensource.org/license/mit/
+
+  This permission notice shall be included in all copies
+    or substantial portions of the Software.
+
+*/
+/*
+  Description: overwrite bytes of a file in place, after making a backup copy
+  (once, so the backup is the file before the first change, not the last).
+*/
+
+// BackupSuffix is added to the name of the copy made before a file is changed
+var BackupSuffix = ".bak"
+
+// BackupFile copies path to path+BackupSuffix, before the first save of an editing session
+func BackupFile(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	_, err = CopyPlace(path, path+BackupSuffix, info.ModTime())
+	return err
+}
+
+// PatchFile overwrites the edited bytes.
+func PatchFile(path string, edits map[int64]byte) error {
+	f, err := os.OpenFile(path, os.O_WRONLY, 0)
+	if err != nil {
+		return err
+	}
+	offsets := make([]int64, 0, len(edits))
+	for offset := range edits {
+		offsets = append(offsets, offset)
+	}
+	sort.Slice(offsets, func(i, j int) bool { return offsets[i] < offsets[j] })
+	for _, offset := range offsets {
+		if _, err = f.WriteAt([]byte{edits[offset]}, offset); err != nil {
+			_ = f.Close()
+			return err
+		}
+	}
+	return f.Close()
+}
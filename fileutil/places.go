@@ -8,6 +8,7 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"time"
 )
 
@@ -106,6 +107,12 @@ func DirTreeList(path string, f func(string) error) {
 	})
 }
 
+// InsideDir is if path is dir, or in it (not a name starting "..", like "..foo")
+func InsideDir(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
 type PlaceType int
 
 const (
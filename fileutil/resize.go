@@ -0,0 +1,219 @@
+package fileutil
+
+import (
+	"errors"
+	"fmt"
+	xdraw "golang.org/x/image/draw"
+	"image"
+	"image/color"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+/*
+
+  File:    resize.go
+  Author:  Bob Shofner
+
+  MIT License - https://opensource.org/license/mit/
+
+  This permission notice shall be included in all copies
+    or substantial portions of the Software.
+
+*/
+/*
+  Description: resize images (to a longest side or a percent) and convert them
+  to PNG, JPEG or GIF. GIF colors are chosen by median cut, which suits
+  screenshots better than a fixed palette.
+*/
+
+// image formats written
+const (
+	FormatSame = "Same"
+	FormatPNG  = "PNG"
+	FormatJPEG = "JPEG"
+	FormatGIF  = "GIF"
+)
+
+var ImageFormats = []string{FormatSame, FormatPNG, FormatJPEG, FormatGIF}
+
+// ResizeOptions - either MaxSide (the longest side, images are only made smaller)
+// or Percent (of the size) if not 0, and the format written
+type ResizeOptions struct {
+	MaxSide   int
+	Percent   int
+	Format    string
+	Overwrite bool
+	EncodeOptions
+}
+
+// ErrExists is a result that would replace a file
+var ErrExists = errors.New("already exists")
+
+// ResizeImage writes an image resized and converted into dir, and is its path.
+// The name is kept, the extension that of the format.
+// Images that can't be written as they are (BMP, TIFF, WebP, SVG) are written as PNG.
+func ResizeImage(path, dir string, options ResizeOptions) (string, error) {
+	name := filepath.Base(path)
+	ext := strings.ToLower(filepath.Ext(name))
+	switch options.Format {
+	case FormatPNG:
+		ext = ".png"
+	case FormatJPEG:
+		ext = ".jpg"
+	case FormatGIF:
+		ext = ".gif"
+	default:
+		switch ext {
+		case ".jpg", ".jpeg", ".jpe", ".jfif", ".png", ".gif":
+		default:
+			ext = ".png"
+		}
+	}
+	out := filepath.Join(dir, strings.TrimSuffix(name, filepath.Ext(name))+ext)
+	if out == path {
+		return "", fmt.Errorf("%s: would replace itself", name)
+	}
+	if _, err := os.Stat(out); err == nil && !options.Overwrite {
+		return "", fmt.Errorf("%s %w", filepath.Base(out), ErrExists)
+	}
+	img, _, err := DecodeImage(path)
+	if err != nil {
+		return "", err
+	}
+	if err = SaveImageWith(out, resize(img, options), options.EncodeOptions); err != nil {
+		return "", fmt.Errorf("%s: %w", filepath.Base(out), err)
+	}
+	return out, nil
+}
+
+// resize scales an image by the options, itself if unchanged
+func resize(img image.Image, options ResizeOptions) image.Image {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	switch {
+	case options.Percent > 0:
+		w, h = max(w*options.Percent/100, 1), max(h*options.Percent/100, 1)
+	case options.MaxSide > 0 && max(w, h) > options.MaxSide:
+		if w >= h {
+			w, h = options.MaxSide, max(h*options.MaxSide/w, 1)
+		} else {
+			w, h = max(w*options.MaxSide/h, 1), options.MaxSide
+		}
+	}
+	if w == bounds.Dx() && h == bounds.Dy() {
+		return img
+	}
+	scaled := image.NewNRGBA(image.Rect(0, 0, w, h))
+	xdraw.CatmullRom.Scale(scaled, scaled.Bounds(), img, bounds, xdraw.Src, nil)
+	return scaled
+}
+
+// medianCut is a draw.Quantizer, that splits the colors used into boxes
+// of about as many pixels, each box a color of the palette.
+// A color is kept for transparency, if any pixels are transparent.
+type medianCut struct{}
+
+type colorCount struct {
+	rgb   [3]uint8
+	count int
+}
+
+func (medianCut) Quantize(palette color.Palette, img image.Image) color.Palette {
+	// colors, to 5 bits a channel
+	counts := make(map[uint32]int)
+	transparent := false
+	bounds := img.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			if c.A < 0x80 {
+				transparent = true
+				continue
+			}
+			counts[uint32(c.R>>3)<<10|uint32(c.G>>3)<<5|uint32(c.B>>3)]++
+		}
+	}
+	n := cap(palette) - len(palette)
+	if transparent {
+		palette = append(palette, color.NRGBA{})
+		n--
+	}
+	colors := make([]colorCount, 0, len(counts))
+	for key, count := range counts {
+		colors = append(colors, colorCount{
+			rgb:   [3]uint8{uint8(key>>10)<<3 | 4, uint8(key>>5&31)<<3 | 4, uint8(key&31)<<3 | 4},
+			count: count,
+		})
+	}
+	if len(colors) == 0 || n < 1 {
+		return palette
+	}
+	boxes := [][]colorCount{colors}
+	for len(boxes) < n {
+		// split the box of the most pixels, that has more than one color
+		split := -1
+		most := 0
+		for i, box := range boxes {
+			if pixels := pixelCount(box); len(box) > 1 && pixels > most {
+				split, most = i, pixels
+			}
+		}
+		if split < 0 {
+			break
+		}
+		box := boxes[split]
+		channel := widestChannel(box)
+		slices.SortFunc(box, func(a, b colorCount) int {
+			return int(a.rgb[channel]) - int(b.rgb[channel])
+		})
+		half, at := 0, 0
+		for at < len(box)-1 && half+box[at].count <= most/2 {
+			half += box[at].count
+			at++
+		}
+		at = max(at, 1)
+		boxes[split] = box[:at]
+		boxes = append(boxes, box[at:])
+	}
+	for _, box := range boxes {
+		var r, g, b, total int
+		for _, c := range box {
+			r += int(c.rgb[0]) * c.count
+			g += int(c.rgb[1]) * c.count
+			b += int(c.rgb[2]) * c.count
+			total += c.count
+		}
+		palette = append(palette, color.NRGBA{R: uint8(r / total), G: uint8(g / total), B: uint8(b / total), A: 0xff})
+	}
+	return palette
+}
+
+func pixelCount(box []colorCount) int {
+	total := 0
+	for _, c := range box {
+		total += c.count
+	}
+	return total
+}
+
+// widestChannel is the channel (R, G or B) of the widest range in a box
+func widestChannel(box []colorCount) int {
+	low := [3]uint8{255, 255, 255}
+	var high [3]uint8
+	for _, c := range box {
+		for i, v := range c.rgb {
+			low[i] = min(low[i], v)
+			high[i] = max(high[i], v)
+		}
+	}
+	widest := 0
+	for i := 1; i < 3; i++ {
+		if high[i]-low[i] > high[widest]-low[widest] {
+			widest = i
+		}
+	}
+	return widest
+}
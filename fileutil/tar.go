@@ -3,8 +3,10 @@ package fileutil
 import (
 	"archive/tar"
 	"io"
+	"io/fs"
 	"os"
-	"path/filepath"
+	"os/user"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -21,14 +23,18 @@ import (
 
 */
 /*
-
- */
+  Description: create, list and extract a tar. Files, folders (empty ones too),
+  symbolic and hard links are kept, with their mode, owner and modified time,
+  as GNU tar does. The owner is only restored when run as root.
+*/
 
 type Tar struct {
 	tarFile string
+	source  *os.File
 	reader  *tar.Reader
 	target  *os.File
 	writer  *tar.Writer
+	options ExtractOptions
 }
 
 func NewUnTar(tarFile string) (*Tar, error) {
@@ -39,6 +45,7 @@ func NewUnTar(tarFile string) (*Tar, error) {
 	r := tar.NewReader(f)
 	z := Tar{
 		tarFile: tarFile,
+		source:  f,
 		reader:  r,
 	}
 	return &z, nil
@@ -58,19 +65,26 @@ func NewTar(tarFile string) (*Tar, error) {
 	return &z, nil
 }
 
-func (z *Tar) Extract(dest string, notDone func()) (int, error) {
-	_ = os.MkdirAll(dest, os.ModePerm)
+// Extract writes the entries (those of the options) into dest, and is the number of files
+func (z *Tar) Extract(dest string, notDone func()) (count int, err error) {
 	defer func() {
+		if z.source != nil {
+			_ = z.source.Close()
+		}
 		z.reader = nil
 	}()
-
-	var out *os.File
+	x, err := newExtractor(dest, z.options)
+	if err != nil {
+		return 0, err
+	}
 	defer func() {
-		if out != nil {
-			_ = out.Close()
+		if unsafe := x.verify(); err == nil {
+			err = unsafe
 		}
 	}()
-	count := 0
+	folders := make(map[string]*tar.Header)
+	order := make([]string, 0)
+	owners := make(map[string]int)
 	for {
 		notDone()
 		header, err := z.reader.Next()
@@ -80,33 +94,141 @@ func (z *Tar) Extract(dest string, notDone func()) (int, error) {
 		if err != nil {
 			return count, err
 		}
-		destination := filepath.Join(dest, header.Name)
-		switch header.Typeflag {
-		case tar.TypeDir:
-			err = os.MkdirAll(destination, os.ModePerm)
-			if err != nil {
+		if !z.options.wanted(header.Name) {
+			continue
+		}
+		destination, err := x.path(header.Name)
+		if err != nil {
+			return count, err
+		}
+		if header.Typeflag == tar.TypeDir {
+			if err = x.mkdir(destination); err != nil {
 				return count, err
 			}
+			// after the files in it, so it can be written, and they don't change its time
+			if folders[destination] == nil {
+				order = append(order, destination)
+			}
+			folders[destination] = header
+			continue
+		}
+		if !z.options.replaces(destination, header.ModTime) {
+			continue
+		}
+		switch header.Typeflag {
 		case tar.TypeReg:
-			err = os.MkdirAll(filepath.Dir(destination), os.ModePerm)
-			out, err = os.Create(destination)
+			err = x.write(destination, z.reader, header.ModTime)
+		case tar.TypeSymlink:
+			err = x.symlink(destination, header.Linkname)
+		case tar.TypeLink:
+			err = x.hardlink(destination, header.Linkname)
+		default:
+			continue
+		}
+		if err != nil {
+			return count, err
+		}
+		if header.Typeflag != tar.TypeLink { // is the file linked to
+			restoreTar(destination, header, owners)
+		}
+		count++
+	}
+	for i := len(order) - 1; i >= 0; i-- {
+		restoreTar(order[i], folders[order[i]], owners)
+	}
+	return count, nil
+}
+
+// restoreTar sets the owner (if root), mode and times of an entry extracted.
+// The owner is by name, as GNU tar, else by id. owners are the names found.
+func restoreTar(path string, header *tar.Header, owners map[string]int) {
+	if os.Geteuid() == 0 {
+		uid, gid := header.Uid, header.Gid
+		if id, ok := tarOwner(owners, "u:"+header.Uname, func() (string, error) {
+			u, err := user.Lookup(header.Uname)
 			if err != nil {
-				out = nil
-				return count, err
+				return "", err
 			}
-			_, err = io.Copy(out, z.reader)
+			return u.Uid, nil
+		}); ok {
+			uid = id
+		}
+		if id, ok := tarOwner(owners, "g:"+header.Gname, func() (string, error) {
+			g, err := user.LookupGroup(header.Gname)
 			if err != nil {
-				return count, err
+				return "", err
 			}
-			_ = os.Chtimes(destination, header.ModTime, header.ModTime)
-			_ = out.Close()
-			count++
-			out = nil
+			return g.Gid, nil
+		}); ok {
+			gid = id
 		}
+		_ = os.Lchown(path, uid, gid)
 	}
-	return count, nil
+	accessed := header.AccessTime
+	if accessed.IsZero() {
+		accessed = header.ModTime
+	}
+	if header.Typeflag == tar.TypeSymlink { // Chmod, Chtimes would change the file linked to
+		_ = linkTimes(path, accessed, header.ModTime)
+		return
+	}
+	keep := fs.ModePerm | fs.ModeSticky
+	if os.Geteuid() == 0 { // as GNU tar, only root keeps setuid and setgid
+		keep |= fs.ModeSetuid | fs.ModeSetgid
+	}
+	_ = os.Chmod(path, header.FileInfo().Mode()&keep)
+	_ = os.Chtimes(path, accessed, header.ModTime)
+}
+
+// tarOwner is the id of a user or group name, -1 in owners if not known
+func tarOwner(owners map[string]int, name string, lookup func() (string, error)) (int, bool) {
+	if len(name) <= 2 {
+		return 0, false
+	}
+	id, found := owners[name]
+	if !found {
+		id = -1
+		if s, err := lookup(); err == nil {
+			if n, err := strconv.Atoi(s); err == nil {
+				id = n
+			}
+		}
+		owners[name] = id
+	}
+	return id, id >= 0
 }
 
+// List is the entries of the tar, at most limit if not 0.
+// cancel returning true ends the listing with io.EOF.
+func (z *Tar) List(limit int, cancel func() bool) ([]ArchiveEntry, error) {
+	entries := make([]ArchiveEntry, 0)
+	for {
+		if cancel() {
+			return entries, io.EOF
+		}
+		if limit > 0 && len(entries) >= limit {
+			return entries, nil
+		}
+		header, err := z.reader.Next()
+		if err == io.EOF {
+			return entries, nil
+		}
+		if err != nil {
+			return entries, err
+		}
+		entries = append(entries, ArchiveEntry{
+			Name:       header.Name,
+			Size:       header.Size,
+			Compressed: header.Size,
+			Modified:   header.ModTime,
+			Mode:       header.FileInfo().Mode(),
+			Link:       header.Linkname,
+		})
+	}
+}
+
+// Compress writes the files, folders and links (not followed), their names from parent.
+// Folders are written as entries, not only the files in them.
 func (z *Tar) Compress(parent string, files []string, notDone func()) error {
 	if len(parent) > 1 {
 		parent += "/"
@@ -117,47 +239,69 @@ func (z *Tar) Compress(parent string, files []string, notDone func()) error {
 	defer func() {
 		_ = z.target.Close()
 	}()
+	written := make(map[fileID]string)
 	for _, file := range files {
 		notDone()
-		err := addTarFile(z.writer, parent, file)
+		err := addTarFile(z.writer, parent, file, written)
 		if err != nil {
 			return err
 		}
 	}
 	return nil
 }
-func addTarFile(tarWriter *tar.Writer, parent string, file string) error {
-	// input file
-	fileToTar, err := os.Open(file)
+
+// addTarFile writes a file, folder or symbolic link as GNU tar does: mode, owner
+// (ids and names), and a PAX header for names too long.
+// A file (hard) linked to one written is a link to it.
+func addTarFile(tarWriter *tar.Writer, parent string, file string, written map[fileID]string) error {
+	info, err := os.Lstat(file)
 	if err != nil {
 		return err
 	}
-	defer func() {
-		_ = fileToTar.Close()
-	}()
-	info, err := fileToTar.Stat()
+	if info.Mode()&fs.ModeSocket != 0 { // as GNU tar, "socket ignored"
+		return nil
+	}
+	link := ""
+	if info.Mode()&fs.ModeSymlink != 0 {
+		if link, err = os.Readlink(file); err != nil {
+			return err
+		}
+	}
+	header, err := tar.FileInfoHeader(info, link)
 	if err != nil {
 		return err
 	}
+	header.Name = strings.ReplaceAll(file[len(parent):], "\\", "/")
 	if info.IsDir() {
-		return nil
+		header.Name += "/"
 	}
-	header := &tar.Header{
-		Size:     info.Size(),
-		Mode:     int64(info.Mode()),
-		ModTime:  info.ModTime(),
-		Typeflag: tar.TypeReg,
+	header.ModTime = header.ModTime.Truncate(time.Second)
+	header.AccessTime = time.Time{}
+	header.ChangeTime = time.Time{}
+	if header.Typeflag == tar.TypeReg {
+		if id, ok := hardLinkID(info); ok {
+			if first, seen := written[id]; seen {
+				header.Typeflag = tar.TypeLink
+				header.Linkname = first
+				header.Size = 0
+			} else {
+				written[id] = header.Name
+			}
+		}
 	}
-	if info.IsDir() {
-		header.Typeflag = tar.TypeDir
+	if err = tarWriter.WriteHeader(header); err != nil {
+		return err
 	}
-	path := file[len(parent):]
-	header.Name = strings.ReplaceAll(path, "\\", "/")
-	loc, _ := time.LoadLocation("Local")
-	header.ModTime = header.ModTime.In(loc)
-	err = tarWriter.WriteHeader(header)
-	if err == nil {
-		_, err = io.Copy(tarWriter, fileToTar)
+	if header.Typeflag != tar.TypeReg {
+		return nil
 	}
-	return nil
+	fileToTar, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = fileToTar.Close()
+	}()
+	_, err = io.Copy(tarWriter, fileToTar)
+	return err
 }
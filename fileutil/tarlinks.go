@@ -0,0 +1,46 @@
+//go:build !windows
+// +build !windows
+
+package fileutil
+
+import (
+	"golang.org/x/sys/unix"
+	"io/fs"
+	"syscall"
+	"time"
+)
+
+/*
+
+  File:    tarlinks.go
+  Author:  Bob Shofner
+
+  MIT License - https://opensource.org/license/mit/
+
+  This permission notice shall be included in all copies
+    or substantial portions of the Software.
+
+*/
+
+// fileID is the device and inode of a file
+type fileID struct {
+	dev uint64
+	ino uint64
+}
+
+// hardLinkID is the id of a file with more than one (hard) link
+func hardLinkID(info fs.FileInfo) (fileID, bool) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok || stat.Nlink < 2 {
+		return fileID{}, false
+	}
+	return fileID{dev: uint64(stat.Dev), ino: uint64(stat.Ino)}, true
+}
+
+// linkTimes sets the times of a symbolic link, not the file linked to
+func linkTimes(path string, accessed, modified time.Time) error {
+	return unix.Lutimes(path, []unix.Timeval{
+		unix.NsecToTimeval(accessed.UnixNano()),
+		unix.NsecToTimeval(modified.UnixNano()),
+	})
+}
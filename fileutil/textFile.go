@@ -0,0 +1,189 @@
+package fileutil
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+/*
+
+  File:    textFile.go
+  Author:  Bob Shofner
+
+  MIT License - https://opensource.org/license/mit/
+
+  This permission notice shall be included in all copies
+    or substantial portions of the Software.
+
+*/
+/*
+  Description: read a whole text file for editing, and write it back
+  in the same encoding, line ending and byte order mark.
+*/
+
+// TextFormat is how a text file is stored
+type TextFormat struct {
+	Encoding   string // one of Encodings
+	LineEnding string // one of LineEndings
+	BOM        bool
+	// Endings are the line endings of each line, when they are mixed,
+	// as the index in LineEndings. Later lines end in LineEnding.
+	Endings string
+}
+
+// ErrNotText is a file that can't be edited as text without changing it
+var ErrNotText = errors.New("not a text file")
+
+// DecodeText converts the start of a file to UTF-8 with \n line endings.
+// Mixed line endings are all read as new lines, and each is written back as it was.
+func DecodeText(content []byte) (string, TextFormat) {
+	sample := content
+	if len(sample) > 64*1024 {
+		sample = sample[:64*1024]
+	}
+	name, bom := DetectEncoding(sample)
+	return decodeText(content, name, bom)
+}
+
+// decodeText converts content in the named encoding, after a bom
+func decodeText(content []byte, name string, bom int) (string, TextFormat) {
+	format := TextFormat{Encoding: name, LineEnding: LF, BOM: bom > 0}
+	decoded, err := TextEncoding(name).NewDecoder().Bytes(content[bom:])
+	if err != nil {
+		decoded = content[bom:]
+	}
+	format.LineEnding = DetectLineEnding(decoded)
+	text := make([]byte, 0, len(decoded))
+	endings := make([]byte, 0)
+	mixed := false
+	for i := 0; i < len(decoded); i++ {
+		ending := -1
+		switch {
+		case decoded[i] == '\n':
+			ending = 0
+		case decoded[i] == '\r' && i+1 < len(decoded) && decoded[i+1] == '\n':
+			ending = 1
+			i++
+		case decoded[i] == '\r':
+			ending = 2
+		}
+		if ending < 0 {
+			text = append(text, decoded[i])
+			continue
+		}
+		text = append(text, '\n')
+		mixed = mixed || LineEndings[ending] != format.LineEnding
+		endings = append(endings, byte('0'+ending))
+	}
+	if mixed {
+		format.Endings = string(endings)
+	}
+	return string(text), format
+}
+
+// endLines replaces the \n line endings of text with those of the format
+func endLines(text string, format TextFormat) string {
+	nl := lineEnding[format.LineEnding]
+	if format.Endings == "" {
+		if nl == "" || nl == "\n" {
+			return text
+		}
+		return strings.ReplaceAll(text, "\n", nl)
+	}
+	var b strings.Builder
+	b.Grow(len(text) + strings.Count(text, "\n"))
+	for line := 0; ; line++ {
+		i := strings.IndexByte(text, '\n')
+		if i < 0 {
+			b.WriteString(text)
+			return b.String()
+		}
+		b.WriteString(text[:i])
+		if line < len(format.Endings) {
+			b.WriteString(lineEnding[LineEndings[format.Endings[line]-'0']])
+		} else {
+			b.WriteString(nl)
+		}
+		text = text[i+1:]
+	}
+}
+
+// EncodeText converts UTF-8 text with \n line endings to the format.
+// It fails on characters the encoding doesn't have.
+func EncodeText(text string, format TextFormat) ([]byte, error) {
+	enc := TextEncoding(format.Encoding)
+	if enc == nil {
+		return nil, errors.New("unknown encoding " + format.Encoding)
+	}
+	text = endLines(text, format)
+	encoded, err := enc.NewEncoder().Bytes([]byte(text))
+	if err != nil {
+		return nil, fmt.Errorf("the text can't be saved as %s: %w", format.Encoding, err)
+	}
+	if mark := ByteOrderMark(format.Encoding); format.BOM && mark != nil {
+		encoded = append(mark, encoded...)
+	}
+	return encoded, nil
+}
+
+// ReadText reads a text file, and how it is stored.
+// Binary files, and files that wouldn't be written back the same
+// (not all valid in the detected encoding), are ErrNotText.
+func ReadText(path string) (string, TextFormat, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", TextFormat{}, err
+	}
+	if LooksBinary(content) {
+		return "", TextFormat{}, fmt.Errorf("%s is binary, %w", filepath.Base(path), ErrNotText)
+	}
+	// the encoding of the whole file
+	name, bom := detectEncoding(content, false)
+	text, format := decodeText(content, name, bom)
+	if same, err := EncodeText(text, format); err != nil || !bytes.Equal(same, content) {
+		return "", TextFormat{}, fmt.Errorf("%s isn't all valid %s, %w", filepath.Base(path), format.Encoding, ErrNotText)
+	}
+	return text, format, nil
+}
+
+// WriteText replaces (or creates) a text file, through a temporary file
+// so a failed write leaves the original. The file mode is kept.
+func WriteText(path, text string, format TextFormat) error {
+	content, err := EncodeText(text, format)
+	if err != nil {
+		return err
+	}
+	return replaceFile(path, content)
+}
+
+// replaceFile writes a file by renaming a temporary copy over it, keeping the permissions
+func replaceFile(path string, content []byte) error {
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+	out, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	tmp := out.Name()
+	if _, err = out.Write(content); err != nil {
+		_ = out.Close()
+		_ = os.Remove(tmp)
+		return err
+	}
+	if err = out.Close(); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	_ = os.Chmod(tmp, mode)
+	if err = os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	return nil
+}
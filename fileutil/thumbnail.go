@@ -0,0 +1,260 @@
+package fileutil
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	xdraw "golang.org/x/image/draw"
+	"hash/crc32"
+	"image"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+/*
+
+  File:    thumbnail.go
+  Author:  Bob Shofner
+
+  MIT License - https://opensource.org/license/mit/
+
+  This permission notice shall be included in all copies
+    or substantial portions of the Software.
+
+*/
+/*
+  Description: image thumbnails, cached as the freedesktop.org thumbnail spec,
+  so they are shared with other desktop tools.
+
+  https://specifications.freedesktop.org/thumbnail-spec/latest/
+
+  A thumbnail is a PNG in ~/.cache/thumbnails/normal (128 pixels) or large (256),
+  named the MD5 of the file's URI, with tEXt chunks of the URI (Thumb::URI) and
+  the file's modification time (Thumb::MTime). It is out of date if the MTime differs.
+  Images that fail are noted in fail/fman, so they aren't tried again.
+*/
+
+const (
+	ThumbnailNormal = 128
+	ThumbnailLarge  = 256
+	thumbnailFail   = "fman"
+)
+
+// ThumbnailDir is the root of the thumbnail cache
+func ThumbnailDir() string {
+	cache := os.Getenv("XDG_CACHE_HOME")
+	if cache == "" || !filepath.IsAbs(cache) {
+		if runtime.GOOS == "windows" {
+			cache, _ = os.UserCacheDir()
+		} else if home, err := os.UserHomeDir(); err == nil {
+			cache = filepath.Join(home, ".cache")
+		}
+	}
+	return filepath.Join(cache, "thumbnails")
+}
+
+// FileURI is the file:// URI of a path, escaped as GLib does
+func FileURI(path string) string {
+	abs, err := filepath.Abs(path)
+	if err == nil {
+		path = abs
+	}
+	path = filepath.ToSlash(path)
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path // a Windows drive
+	}
+	var uri strings.Builder
+	uri.WriteString("file://")
+	for _, b := range []byte(path) {
+		switch {
+		case 'a' <= b && b <= 'z', 'A' <= b && b <= 'Z', '0' <= b && b <= '9',
+			strings.IndexByte("-._~!$&'()*+,=:@/", b) >= 0:
+			uri.WriteByte(b)
+		default:
+			_, _ = fmt.Fprintf(&uri, "%%%02X", b)
+		}
+	}
+	return uri.String()
+}
+
+// thumbnailName is the file name of a thumbnail, the MD5 of the URI
+func thumbnailName(uri string) string {
+	sum := md5.Sum([]byte(uri))
+	return hex.EncodeToString(sum[:]) + ".png"
+}
+
+func thumbnailFolder(size int) string {
+	if size > ThumbnailNormal {
+		return "large"
+	}
+	return "normal"
+}
+
+// Thumbnail is the thumbnail of an image, of size ThumbnailNormal or ThumbnailLarge.
+// It is read from the cache, or made (and cached) if not there or out of date.
+func Thumbnail(path string, size int) (image.Image, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return nil, fmt.Errorf("%s is a directory", filepath.Base(path))
+	}
+	root := ThumbnailDir()
+	uri := FileURI(path)
+	name := thumbnailName(uri)
+	mtime := strconv.FormatInt(info.ModTime().Unix(), 10)
+	cached := filepath.Join(root, thumbnailFolder(size), name)
+	if img, ok := readThumbnail(cached, uri, mtime); ok {
+		return img, nil
+	}
+	failed := filepath.Join(root, "fail", thumbnailFail, name)
+	if _, ok := readThumbnail(failed, uri, mtime); ok {
+		return nil, fmt.Errorf("%s: no thumbnail can be made", filepath.Base(path))
+	}
+
+	img, _, err := DecodeImage(path)
+	if err != nil {
+		if !InsideDir(path, root) {
+			_ = writeThumbnail(failed, image.NewNRGBA(image.Rect(0, 0, 1, 1)), uri, mtime, info.Size(), image.Rectangle{})
+		}
+		return nil, err
+	}
+	thumb := scaleImage(img, size)
+	if !InsideDir(path, root) { // no thumbnails of thumbnails
+		_ = writeThumbnail(cached, thumb, uri, mtime, info.Size(), img.Bounds())
+	}
+	return thumb, nil
+}
+
+// scaleImage scales an image down to fit in size x size
+func scaleImage(img image.Image, size int) image.Image {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	if w <= size && h <= size {
+		return img
+	}
+	if w >= h {
+		w, h = size, max(h*size/w, 1)
+	} else {
+		w, h = max(w*size/h, 1), size
+	}
+	scaled := image.NewNRGBA(image.Rect(0, 0, w, h))
+	xdraw.BiLinear.Scale(scaled, scaled.Bounds(), img, bounds, xdraw.Src, nil)
+	return scaled
+}
+
+// readThumbnail reads a cached thumbnail, if it is of the URI and mtime
+func readThumbnail(path, uri, mtime string) (image.Image, bool) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+	text, err := pngText(content)
+	if err != nil || text["Thumb::MTime"] != mtime || text["Thumb::URI"] != uri {
+		return nil, false
+	}
+	img, err := png.Decode(bytes.NewReader(content))
+	if err != nil {
+		return nil, false
+	}
+	return img, true
+}
+
+// pngText is the tEXt chunks of a PNG, up to the image data
+func pngText(content []byte) (map[string]string, error) {
+	if len(content) < 8 || string(content[:8]) != "\x89PNG\r\n\x1a\n" {
+		return nil, errors.New("not a PNG")
+	}
+	text := make(map[string]string)
+	for at := 8; at+8 <= len(content); {
+		length := int(binary.BigEndian.Uint32(content[at:]))
+		kind := string(content[at+4 : at+8])
+		if kind == "IDAT" || kind == "IEND" || length < 0 || at+12+length > len(content) {
+			break
+		}
+		if kind == "tEXt" {
+			data := content[at+8 : at+8+length]
+			if key, value, ok := bytes.Cut(data, []byte{0}); ok {
+				text[string(key)] = string(value)
+			}
+		}
+		at += 12 + length
+	}
+	return text, nil
+}
+
+// writeThumbnail writes a PNG with the spec's tEXt chunks, after the IHDR.
+// The cache is private to the user (0700 / 0600).
+func writeThumbnail(path string, img image.Image, uri, mtime string, size int64, original image.Rectangle) error {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return err
+	}
+	content := buf.Bytes()
+	if len(content) < 33 {
+		return errors.New("PNG too short")
+	}
+	var chunks bytes.Buffer
+	text := [][2]string{
+		{"Thumb::URI", uri},
+		{"Thumb::MTime", mtime},
+		{"Thumb::Size", strconv.FormatInt(size, 10)},
+		{"Software", "fman"},
+	}
+	if !original.Empty() {
+		text = append(text,
+			[2]string{"Thumb::Image::Width", strconv.Itoa(original.Dx())},
+			[2]string{"Thumb::Image::Height", strconv.Itoa(original.Dy())})
+	}
+	for _, kv := range text {
+		writePNGChunk(&chunks, "tEXt", []byte(kv[0]+"\x00"+kv[1]))
+	}
+	ihdr := 8 + 12 + 13 // signature, and the IHDR chunk
+	thumb := make([]byte, 0, len(content)+chunks.Len())
+	thumb = append(thumb, content[:ihdr]...)
+	thumb = append(thumb, chunks.Bytes()...)
+	thumb = append(thumb, content[ihdr:]...)
+
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	out, err := os.CreateTemp(dir, "fman-*.png")
+	if err != nil {
+		return err
+	}
+	tmp := out.Name()
+	_, err = out.Write(thumb)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		_ = os.Chmod(tmp, 0600)
+		err = os.Rename(tmp, path)
+	}
+	if err != nil {
+		_ = os.Remove(tmp)
+	}
+	return err
+}
+
+func writePNGChunk(w io.Writer, kind string, data []byte) {
+	header := make([]byte, 8)
+	binary.BigEndian.PutUint32(header, uint32(len(data)))
+	copy(header[4:], kind)
+	crc := crc32.NewIEEE()
+	_, _ = crc.Write(header[4:])
+	_, _ = crc.Write(data)
+	_, _ = w.Write(header)
+	_, _ = w.Write(data)
+	_ = binary.Write(w, binary.BigEndian, crc.Sum32())
+}
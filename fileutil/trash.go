@@ -0,0 +1,128 @@
+//go:build !windows
+// +build !windows
+
+package fileutil
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+/*
+
+  File:    trash.go
+  Author:  Bob Shofner
+
+  MIT License - https://opensource.org/license/mit/
+
+  This permission notice shall be included in all copies
+    or substantial portions of the Software.
+
+*/
+/*
+  Description: move files to the desktop Trash (freedesktop.org Trash specification).
+*/
+
+// MoveToTrash moves a file or directory to the user's Trash
+func MoveToTrash(path string) error {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	if _, err = os.Lstat(path); err != nil {
+		return err
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return err
+	}
+	if runtime.GOOS == "darwin" {
+		return trashRename(path, filepath.Join(home, ".Trash"), false)
+	}
+	dataHome := os.Getenv("XDG_DATA_HOME")
+	if dataHome == "" {
+		dataHome = filepath.Join(home, ".local", "share")
+	}
+	err = trashRename(path, filepath.Join(dataHome, "Trash"), true)
+	if !errors.Is(err, syscall.EXDEV) {
+		return err
+	}
+	// on another file system, use the Trash at its top directory
+	top := mountPoint(path)
+	if top == "" {
+		return err
+	}
+	return trashRename(path, filepath.Join(top, ".Trash-"+strconv.Itoa(os.Getuid())), true)
+}
+
+// trashRename moves path into trash/files, and records it in trash/info
+func trashRename(path, trash string, info bool) error {
+	files := filepath.Join(trash, "files")
+	infos := filepath.Join(trash, "info")
+	if err := os.MkdirAll(files, 0700); err != nil {
+		return err
+	}
+	if info {
+		if err := os.MkdirAll(infos, 0700); err != nil {
+			return err
+		}
+	}
+	base := filepath.Base(path)
+	for n := 1; n < 1000; n++ {
+		name := base
+		if n > 1 {
+			name = fmt.Sprintf("%s.%d", base, n)
+		}
+		if _, err := os.Lstat(filepath.Join(files, name)); err == nil {
+			continue
+		}
+		if !info {
+			return os.Rename(path, filepath.Join(files, name))
+		}
+		// the .trashinfo file reserves the name
+		infoFile := filepath.Join(infos, name+".trashinfo")
+		f, err := os.OpenFile(infoFile, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+		if os.IsExist(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		u := url.URL{Path: path}
+		_, err = fmt.Fprintf(f, "[Trash Info]\nPath=%s\nDeletionDate=%s\n",
+			u.EscapedPath(), time.Now().Format("2006-01-02T15:04:05"))
+		_ = f.Close()
+		if err == nil {
+			err = os.Rename(path, filepath.Join(files, name))
+		}
+		if err != nil {
+			_ = os.Remove(infoFile)
+		}
+		return err
+	}
+	return errors.New("unable to name file in Trash " + base)
+}
+
+// mountPoint finds the top directory of the file system holding path
+func mountPoint(path string) string {
+	var st syscall.Stat_t
+	if syscall.Stat(path, &st) != nil {
+		return ""
+	}
+	dev := st.Dev
+	dir := filepath.Dir(path)
+	for {
+		parent := filepath.Dir(dir)
+		if parent == dir || syscall.Stat(parent, &st) != nil || st.Dev != dev {
+			return dir
+		}
+		dir = parent
+	}
+}
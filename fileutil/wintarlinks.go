@@ -0,0 +1,35 @@
+//go:build windows
+
+package fileutil
+
+import (
+	"io/fs"
+	"time"
+)
+
+/*
+
+  File:    wintarlinks.go
+  Author:  Bob Shofner
+
+  MIT License - https://opensource.org/license/mit/
+
+  This permission notice shall be included in all copies
+    or substantial portions of the Software.
+
+*/
+
+type fileID struct {
+	dev uint64
+	ino uint64
+}
+
+// hardLinkID - the file index isn't in a FileInfo from Lstat, links are written as files
+func hardLinkID(_ fs.FileInfo) (fileID, bool) {
+	return fileID{}, false
+}
+
+// linkTimes - the times of a link aren't set
+func linkTimes(_ string, _, _ time.Time) error {
+	return nil
+}
@@ -3,8 +3,8 @@ package fileutil
 import (
 	"archive/zip"
 	"io"
+	"io/fs"
 	"os"
-	"path/filepath"
 	"strings"
 	"time"
 )
@@ -28,6 +28,7 @@ type Zipper struct {
 	zipFile string
 	reader  *zip.ReadCloser
 	target  *os.File
+	options ExtractOptions
 }
 
 func NewUnZipper(zipFile string) (*Zipper, error) {
@@ -54,58 +55,101 @@ func NewZipper(zipFile string) (*Zipper, error) {
 	return &z, nil
 }
 
-func (z *Zipper) Extract(dest string, notDone func()) error {
-	e := os.MkdirAll(dest, os.ModePerm)
-	if e != nil {
-		return e
+// Extract writes the entries (those of the options) into dest, and is the number of files
+func (z *Zipper) Extract(dest string, notDone func()) (count int, err error) {
+	defer func() {
+		_ = z.reader.Close()
+		z.reader = nil
+	}()
+	x, err := newExtractor(dest, z.options)
+	if err != nil {
+		return 0, err
 	}
-	var out *os.File
 	defer func() {
-		if out != nil {
-			_ = out.Close()
+		if unsafe := x.verify(); err == nil {
+			err = unsafe
 		}
-		z.reader = nil
 	}()
 	for _, file := range z.reader.File {
 		notDone()
-		isDir := file.FileInfo().IsDir()
-		if isDir {
-			dir := filepath.Dir(file.Name)
-			path := filepath.Join(dest, dir)
-			e := os.MkdirAll(path, os.ModePerm)
-			if e != nil {
-				return e
-			}
+		if !z.options.wanted(file.Name) {
 			continue
 		}
-		// parent of this file
-		parent := filepath.Dir(file.Name)
-		if parent != "" && parent != "." {
-			path := filepath.Join(dest, parent)
-			em := os.MkdirAll(path, os.ModePerm)
-			if em != nil {
-				return em
-			}
-		}
-		destination := filepath.Join(dest, file.Name)
-		out, err := os.Create(destination)
+		destination, err := x.path(file.Name)
 		if err != nil {
-			out = nil
-			return err
+			return count, err
 		}
-		fr, err := file.Open()
-		if err != nil {
-			return err
+		switch mode := file.Mode(); {
+		case mode.IsDir():
+			if err = x.mkdir(destination); err != nil {
+				return count, err
+			}
+		case mode&fs.ModeSymlink != 0:
+			if !z.options.replaces(destination, file.Modified) {
+				continue
+			}
+			if err = extractZipLink(x, file, destination); err != nil {
+				return count, err
+			}
+			count++
+		default:
+			if !z.options.replaces(destination, file.Modified) {
+				continue
+			}
+			if err = extractZipFile(x, file, destination); err != nil {
+				return count, err
+			}
+			count++
 		}
-		_, err = io.Copy(out, fr)
-		if err != nil {
-			return err
+	}
+	return count, nil
+}
+
+func extractZipFile(x *extractor, file *zip.File, destination string) error {
+	fr, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = fr.Close()
+	}()
+	return x.write(destination, fr, file.Modified)
+}
+
+// extractZipLink makes a symbolic link, its target is the content
+func extractZipLink(x *extractor, file *zip.File, destination string) error {
+	fr, err := file.Open()
+	if err != nil {
+		return err
+	}
+	target, err := io.ReadAll(io.LimitReader(fr, 4096))
+	_ = fr.Close()
+	if err != nil {
+		return err
+	}
+	return x.symlink(destination, string(target))
+}
+
+// List is the entries of the zip, at most limit if not 0.
+// cancel returning true ends the listing with io.EOF.
+func (z *Zipper) List(limit int, cancel func() bool) ([]ArchiveEntry, error) {
+	entries := make([]ArchiveEntry, 0, len(z.reader.File))
+	for _, file := range z.reader.File {
+		if cancel() {
+			return entries, io.EOF
 		}
-		_ = os.Chtimes(destination, file.Modified, file.Modified)
-		_ = out.Close()
-		out = nil
+		if limit > 0 && len(entries) >= limit {
+			break
+		}
+		entries = append(entries, ArchiveEntry{
+			Name:       file.Name,
+			Size:       int64(file.UncompressedSize64),
+			Compressed: int64(file.CompressedSize64),
+			Modified:   file.Modified,
+			Mode:       file.Mode(),
+		})
 	}
-	return nil
+	return entries, nil
 }
 
 func (z *Zipper) Compress(parent string, files []string, notDone func()) error {
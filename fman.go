@@ -66,6 +66,7 @@ func main() {
 	}
 	system.Settings = settings
 	system.App.Settings().SetTheme(element.NewTheme(system.App.Preferences()))
+	app.StartIndexer(system)
 
 	///// build the visual elements \\\\\
 
@@ -78,13 +79,13 @@ func main() {
 	aTool := container.NewHBox(
 		aPanel.Refresh, aPanel.MarkAll, aPanel.New, aPanel.Home,
 		aPanel.Places, aPanel.Find, aPanel.History, aPanel.Copy,
-		aPanel.Delete,
+		aPanel.Delete, aPanel.Thumbs, aPanel.Preview,
 		aPanel.Source)
 	bTool := container.NewHBox(
 		bPanel.Source,
 		bPanel.Refresh, bPanel.MarkAll, bPanel.New, bPanel.Home,
 		bPanel.Places, bPanel.Find, bPanel.History, bPanel.Copy,
-		bPanel.Delete)
+		bPanel.Delete, bPanel.Thumbs, bPanel.Preview)
 	leftPane := container.NewBorder(aTool, nil, nil, nil, aBox)
 	rightPane := container.NewBorder(bTool, nil, nil, nil, bBox)
 
@@ -93,10 +94,14 @@ func main() {
 	content := container.NewBorder(nil, bottom, nil, nil, center)
 
 	// application cleanup
+	system.MainWindow.SetCloseIntercept(func() {
+		app.ConfirmQuit(system.MainWindow, system.MainWindow.Close)
+	})
 	system.MainWindow.SetOnClosed(func() {
 		control.ClosePrefs()
 		_ = sys.SavePrefs(system.Settings)
 		app.CloseAppWindows()
+		app.StopIndexer()
 	})
 	system.MainWindow.SetContent(content)
 	system.MainWindow.Resize(fyne.NewSize(800, 600))
@@ -142,5 +142,11 @@ func createImageAssoc() FileAssoc {
 	d.Extensions = append(d.Extensions, ".png")
 	d.Extensions = append(d.Extensions, ".jpg")
 	d.Extensions = append(d.Extensions, ".jpeg")
+	d.Extensions = append(d.Extensions, ".gif")
+	d.Extensions = append(d.Extensions, ".bmp")
+	d.Extensions = append(d.Extensions, ".tif")
+	d.Extensions = append(d.Extensions, ".tiff")
+	d.Extensions = append(d.Extensions, ".webp")
+	d.Extensions = append(d.Extensions, ".svg")
 	return d
 }
@@ -47,6 +47,7 @@ type Prefs struct {
 	History        []string    `json:"history"`
 	Favorites      []string    `json:"favorites"`
 	Edit           []string    `json:"edit"`
+	ExternalEdit   bool        `json:"externaledit"`
 	Assoc          []FileAssoc `json:"assoc"`
 	Batch          []string    `json:"batch"`
 	Browser        string      `json:"browser"`
@@ -54,6 +55,10 @@ type Prefs struct {
 	Text           int         `json:"text"`
 	Font           int         `json:"font"`
 	PowerShell     bool        `json:"powershell"`
+	IndexRoots     []string    `json:"indexroots"`
+	IndexContent   bool        `json:"indexcontent"`
+	ExtractMB      int64       `json:"extractmb"`      // largest archive extracted, 0 for no limit
+	ExtractEntries int         `json:"extractentries"` // most entries extracted, 0 for no limit
 	Path           string
 	hidden         *widget.Check
 	monospace      *widget.Check
@@ -85,6 +90,24 @@ func (p *Prefs) GetBrowser() string {
 func (p *Prefs) SetBrowser(browser string) {
 	p.Browser = browser
 }
+func (p *Prefs) SetIndexRoots(roots []string) {
+	p.IndexRoots = roots
+}
+func (p *Prefs) SetIndexContent(t bool) {
+	p.IndexContent = t
+}
+func (p *Prefs) SetExtractLimits(mb int64, entries int) {
+	p.ExtractMB = mb
+	p.ExtractEntries = entries
+}
+
+// ExtractLimits are the bytes and entries an archive may extract
+func (p *Prefs) ExtractLimits() (int64, int) {
+	return p.ExtractMB * 1024 * 1024, p.ExtractEntries
+}
+func (p *Prefs) SetExternalEdit(t bool) {
+	p.ExternalEdit = t
+}
 func (p *Prefs) SetFavorites(favorites []string) {
 	p.Favorites = favorites
 }
@@ -284,6 +307,9 @@ var defaultLinuxBrowser = "firefox"
 var defaultWinBrowseCmd = [...]string{"<BROWSER>", "<URL>"}
 var defaultLinuxBrowseCmd = [...]string{"bash", "-c", "<PATH>"}
 
+const defaultExtractMB = 8192
+const defaultExtractEntries = 100000
+
 func NewPrefs(path string) *Prefs {
 	p := Prefs{}
 	p.DateTimeFormat = defaultDateTimeFormat
@@ -293,6 +319,8 @@ func NewPrefs(path string) *Prefs {
 	p.Favorites = make([]string, 0)
 	p.Assoc = make([]FileAssoc, 0)
 	p.Text = 20
+	p.ExtractMB = defaultExtractMB
+	p.ExtractEntries = defaultExtractEntries
 	p.PowerShell = false
 	setDefaultAssociations(&p)
 	setDefaultEditCmd(&p)